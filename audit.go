@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// auditEntry records a single Sentry API call.
+type auditEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Method     string    `json:"method"`
+	Endpoint   string    `json:"endpoint"`
+	Server     string    `json:"server,omitempty"`
+	Status     int       `json:"status,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// auditLog collects the API calls made during a run. A nil *auditLog
+// discards all records.
+type auditLog struct {
+	mu      sync.Mutex
+	token   string
+	entries []auditEntry
+}
+
+func newAuditLog(token string) *auditLog {
+	return &auditLog{token: token}
+}
+
+// record appends an entry, redacting the auth token from its text fields.
+func (a *auditLog) record(e auditEntry) {
+	if a == nil {
+		return
+	}
+	if a.token != "" {
+		e.Endpoint = strings.ReplaceAll(e.Endpoint, a.token, "[REDACTED]")
+		e.Error = strings.ReplaceAll(e.Error, a.token, "[REDACTED]")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, e)
+}
+
+// list returns the recorded entries in call order.
+func (a *auditLog) list() []auditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]auditEntry{}, a.entries...)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestAuditLogRedactsToken(t *testing.T) {
+	a := newAuditLog("secret-token")
+	a.record(auditEntry{Method: http.MethodGet, Endpoint: "/x/?t=secret-token", Error: "bad token secret-token"})
+
+	var nilLog *auditLog
+	nilLog.record(auditEntry{Method: http.MethodGet})
+
+	entries := a.list()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Endpoint != "/x/?t=[REDACTED]" || entries[0].Error != "bad token [REDACTED]" {
+		t.Errorf("expected token to be redacted, got %+v", entries[0])
+	}
+}
+
+func TestExecuteAuditLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	p := &SentryPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"auth_token": "test-token",
+			"org":        "my-org",
+			"project":    "my-project",
+			"url":        server.URL,
+			"audit":      true,
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	entries, ok := resp.Outputs["audit_log"].([]auditEntry)
+	if !ok || len(entries) != 2 {
+		t.Fatalf("expected deploy and finalize calls in audit_log, got %v", resp.Outputs["audit_log"])
+	}
+	if entries[0].Method != http.MethodPost || entries[0].Endpoint != "/organizations/my-org/releases/1.0.0/deploys/" || entries[0].Status != http.StatusInternalServerError {
+		t.Errorf("unexpected deploy entry: %+v", entries[0])
+	}
+	if entries[1].Method != http.MethodPut || entries[1].Status != http.StatusOK || entries[1].Timestamp.IsZero() {
+		t.Errorf("unexpected finalize entry: %+v", entries[1])
+	}
+}
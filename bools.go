@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// parseBool parses a boolean given as a bool, a number (1 or 0), or a
+// common string form such as "true", "1", "yes", or "off", as YAML and
+// environment variables often yield.
+func parseBool(v any) (bool, error) {
+	switch val := v.(type) {
+	case bool:
+		return val, nil
+	case int:
+		if val == 0 || val == 1 {
+			return val == 1, nil
+		}
+	case float64:
+		if val == 0 || val == 1 {
+			return val == 1, nil
+		}
+	case string:
+		switch strings.ToLower(strings.TrimSpace(val)) {
+		case "true", "t", "1", "yes", "y", "on":
+			return true, nil
+		case "false", "f", "0", "no", "n", "off":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("unrecognized boolean %v: use true or false", v)
+}
+
+// getBool returns the boolean at key, or defaultVal when the key is missing
+// or unrecognized. Unrecognized values are reported by validateBool.
+func getBool(raw map[string]any, key string, defaultVal bool) bool {
+	v, ok := raw[key]
+	if !ok || v == nil || v == "" {
+		return defaultVal
+	}
+	b, err := parseBool(v)
+	if err != nil {
+		return defaultVal
+	}
+	return b
+}
+
+// validateBool returns a warning for field when key is set to a value that
+// is not a recognized boolean, since the default is used instead.
+func validateBool(raw map[string]any, key, field string, defaultVal bool) *plugin.ValidationError {
+	v, ok := raw[key]
+	if !ok || v == nil || v == "" {
+		return nil
+	}
+	if _, err := parseBool(v); err != nil {
+		return &plugin.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("Invalid %s: %v; using the default (%t)", field, err, defaultVal),
+			Code:    validationWarningCode,
+		}
+	}
+	return nil
+}
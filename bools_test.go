@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		input    any
+		expected bool
+		wantErr  bool
+	}{
+		{true, true, false},
+		{false, false, false},
+		{"true", true, false},
+		{"false", false, false},
+		{"1", true, false},
+		{"0", false, false},
+		{"yes", true, false},
+		{"no", false, false},
+		{" On ", true, false},
+		{"OFF", false, false},
+		{1, true, false},
+		{0.0, false, false},
+		{"maybe", false, true},
+		{2, false, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseBool(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBool(%v) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("parseBool(%v) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestParseConfigBoolStrings(t *testing.T) {
+	p := &SentryPlugin{}
+	cfg := p.parseConfig(map[string]any{
+		"set_commits":       "no",
+		"create_deploy":     "0",
+		"finalize":          "maybe",
+		"upload_sourcemaps": "yes",
+		"commits":           map[string]any{"auto": "false"},
+	})
+
+	if cfg.SetCommits || cfg.CreateDeploy || !cfg.UploadSourcemaps || cfg.Commits.Auto {
+		t.Errorf("unexpected coerced booleans: %+v", cfg)
+	}
+	if !cfg.Finalize {
+		t.Error("expected unrecognized finalize value to keep the default")
+	}
+}
+
+func TestValidateBoolWarnings(t *testing.T) {
+	p := &SentryPlugin{}
+	resp, err := p.Validate(context.Background(), map[string]any{
+		"auth_token":  "test-token",
+		"project":     "my-project",
+		"set_commits": "yes",
+		"finalize":    "maybe",
+		"commits":     map[string]any{"auto": "sometimes"},
+	})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	warned := make(map[string]bool)
+	for _, e := range resp.Errors {
+		if e.Code == validationWarningCode {
+			warned[e.Field] = true
+		}
+	}
+	if !warned["finalize"] || !warned["commits.auto"] || warned["set_commits"] {
+		t.Errorf("expected warnings for finalize and commits.auto only, got %+v", resp.Errors)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// checksumVersionInfoPrefix prefixes artifact names in release version info.
+const checksumVersionInfoPrefix = "checksum."
+
+// readChecksumFile reads artifact checksums in the format written by
+// sha256sum and similar tools: one "<checksum>  <name>" entry per line.
+func readChecksumFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact checksums file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid artifact checksums file %s: line %d is not \"<checksum> <name>\"", path, line)
+		}
+		// A leading "*" marks files hashed in binary mode
+		checksums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read artifact checksums file: %w", err)
+	}
+	return checksums, nil
+}
+
+// checksumVersionInfo returns the artifact checksums as release version
+// info entries keyed by "checksum.<name>".
+func checksumVersionInfo(checksums map[string]string) map[string]string {
+	if len(checksums) == 0 {
+		return nil
+	}
+	info := make(map[string]string, len(checksums))
+	for name, sum := range checksums {
+		info[checksumVersionInfoPrefix+name] = sum
+	}
+	return info
+}
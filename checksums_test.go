@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadChecksumFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SHA256SUMS")
+	content := "# build outputs\n" +
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  app.js\n" +
+		"\n" +
+		"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08 *app.wasm\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checksums, err := readChecksumFile(path)
+	if err != nil {
+		t.Fatalf("readChecksumFile() error = %v", err)
+	}
+	if len(checksums) != 2 ||
+		checksums["app.js"] != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" ||
+		checksums["app.wasm"] != "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08" {
+		t.Errorf("unexpected checksums: %v", checksums)
+	}
+
+	if err := os.WriteFile(path, []byte("not a checksum line here\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readChecksumFile(path); err == nil {
+		t.Error("expected error for malformed line")
+	}
+}
+
+func TestChecksumVersionInfo(t *testing.T) {
+	if info := checksumVersionInfo(nil); info != nil {
+		t.Errorf("expected no version info without checksums, got %v", info)
+	}
+
+	info := checksumVersionInfo(map[string]string{"app.js": "abc"})
+	if len(info) != 1 || info["checksum.app.js"] != "abc" {
+		t.Errorf("unexpected version info: %v", info)
+	}
+}
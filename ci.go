@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// CIMetadata describes the CI run that produced a release.
+type CIMetadata struct {
+	Provider string `json:"provider"`
+	BuildID  string `json:"build_id,omitempty"`
+	BuildURL string `json:"build_url,omitempty"`
+	JobName  string `json:"job_name,omitempty"`
+}
+
+// envLookup returns a lookup function that prefers the release context
+// environment and falls back to the process environment.
+func envLookup(env map[string]string) func(string) string {
+	return func(key string) string {
+		if v, ok := env[key]; ok && v != "" {
+			return v
+		}
+		return os.Getenv(key)
+	}
+}
+
+// detectCI detects the CI provider from well-known environment variables.
+// It returns nil when no supported provider is detected.
+func detectCI(getenv func(string) string) *CIMetadata {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		meta := &CIMetadata{
+			Provider: "github-actions",
+			BuildID:  getenv("GITHUB_RUN_ID"),
+			JobName:  getenv("GITHUB_JOB"),
+		}
+		server := getenv("GITHUB_SERVER_URL")
+		if server == "" {
+			server = "https://github.com"
+		}
+		if repo := getenv("GITHUB_REPOSITORY"); repo != "" && meta.BuildID != "" {
+			meta.BuildURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, meta.BuildID)
+		}
+		return meta
+	case getenv("GITLAB_CI") == "true":
+		return &CIMetadata{
+			Provider: "gitlab-ci",
+			BuildID:  getenv("CI_JOB_ID"),
+			BuildURL: getenv("CI_JOB_URL"),
+			JobName:  getenv("CI_JOB_NAME"),
+		}
+	case getenv("CIRCLECI") == "true":
+		return &CIMetadata{
+			Provider: "circleci",
+			BuildID:  getenv("CIRCLE_BUILD_NUM"),
+			BuildURL: getenv("CIRCLE_BUILD_URL"),
+			JobName:  getenv("CIRCLE_JOB"),
+		}
+	}
+	return nil
+}
+
+// versionInfo returns the CI metadata as release version info entries.
+func (m *CIMetadata) versionInfo() map[string]string {
+	info := map[string]string{"ci_provider": m.Provider}
+	if m.BuildID != "" {
+		info["ci_build_id"] = m.BuildID
+	}
+	if m.BuildURL != "" {
+		info["ci_build_url"] = m.BuildURL
+	}
+	if m.JobName != "" {
+		info["ci_job_name"] = m.JobName
+	}
+	return info
+}
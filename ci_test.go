@@ -0,0 +1,83 @@
+package main
+
+import "testing"
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantProvider string
+		wantBuildID  string
+		wantBuildURL string
+	}{
+		{
+			name:         "no CI",
+			env:          map[string]string{},
+			wantProvider: "",
+		},
+		{
+			name: "github actions",
+			env: map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_RUN_ID":     "12345",
+				"GITHUB_REPOSITORY": "org/repo",
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_JOB":        "release",
+			},
+			wantProvider: "github-actions",
+			wantBuildID:  "12345",
+			wantBuildURL: "https://github.com/org/repo/actions/runs/12345",
+		},
+		{
+			name: "gitlab ci",
+			env: map[string]string{
+				"GITLAB_CI":  "true",
+				"CI_JOB_ID":  "678",
+				"CI_JOB_URL": "https://gitlab.com/org/repo/-/jobs/678",
+			},
+			wantProvider: "gitlab-ci",
+			wantBuildID:  "678",
+			wantBuildURL: "https://gitlab.com/org/repo/-/jobs/678",
+		},
+		{
+			name: "circleci",
+			env: map[string]string{
+				"CIRCLECI":         "true",
+				"CIRCLE_BUILD_NUM": "42",
+				"CIRCLE_BUILD_URL": "https://circleci.com/gh/org/repo/42",
+			},
+			wantProvider: "circleci",
+			wantBuildID:  "42",
+			wantBuildURL: "https://circleci.com/gh/org/repo/42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := detectCI(func(key string) string { return tt.env[key] })
+			if tt.wantProvider == "" {
+				if meta != nil {
+					t.Fatalf("expected no CI metadata, got %+v", meta)
+				}
+				return
+			}
+			if meta == nil {
+				t.Fatal("expected CI metadata, got nil")
+			}
+			if meta.Provider != tt.wantProvider {
+				t.Errorf("Provider = %q, want %q", meta.Provider, tt.wantProvider)
+			}
+			if meta.BuildID != tt.wantBuildID {
+				t.Errorf("BuildID = %q, want %q", meta.BuildID, tt.wantBuildID)
+			}
+			if meta.BuildURL != tt.wantBuildURL {
+				t.Errorf("BuildURL = %q, want %q", meta.BuildURL, tt.wantBuildURL)
+			}
+
+			info := meta.versionInfo()
+			if info["ci_provider"] != tt.wantProvider {
+				t.Errorf("versionInfo ci_provider = %q, want %q", info["ci_provider"], tt.wantProvider)
+			}
+		})
+	}
+}
@@ -4,11 +4,22 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,30 +27,110 @@ const defaultTimeout = 30 * time.Second
 
 // SentryClient wraps the Sentry API.
 type SentryClient struct {
-	baseURL    string
-	authToken  string
-	org        string
-	httpClient *http.Client
+	baseURL string
+	// fallbackURL serves requests the primary baseURL cannot be reached for.
+	fallbackURL string
+	authToken   string
+	org         string
+	httpClient  *http.Client
+	timeouts    map[string]time.Duration
+	metrics     *runMetrics
+	audit       *auditLog
+	apiTime     *apiTimer
+	retry       RetryConfig
+	limiter     *rateLimiter
+	debug       *debugLogger
+}
+
+// apiTimer accumulates the wall-clock time spent in Sentry API calls. A nil
+// *apiTimer discards all time.
+type apiTimer struct {
+	total atomic.Int64
+}
+
+// add records the duration of a call.
+func (t *apiTimer) add(d time.Duration) {
+	if t != nil {
+		t.total.Add(int64(d))
+	}
+}
+
+// milliseconds returns the total time recorded.
+func (t *apiTimer) milliseconds() int64 {
+	return time.Duration(t.total.Load()).Milliseconds()
+}
+
+// ClientOptions contains optional transport settings for the Sentry client.
+type ClientOptions struct {
+	// TLSServerName overrides the hostname used for SNI and certificate verification.
+	TLSServerName string
+	// Timeouts overrides the default timeout of each request attempt per
+	// endpoint category.
+	Timeouts map[string]time.Duration
+	// Retry controls which error responses are retried.
+	Retry RetryConfig
+	// RateLimit caps the requests sent per second; zero means unlimited.
+	RateLimit float64
+	// FallbackURL is a secondary Sentry URL used when the primary cannot be
+	// reached.
+	FallbackURL string
+	// ProxyURL routes requests through the given proxy. When empty, the
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used.
+	ProxyURL string
+	// RootCAs replaces the system roots used to verify the server's
+	// certificate; nil uses the system roots.
+	RootCAs *x509.CertPool
+	// InsecureSkipVerify disables certificate verification entirely.
+	InsecureSkipVerify bool
 }
 
 // NewSentryClient creates a new Sentry API client.
-func NewSentryClient(baseURL, authToken, org string) *SentryClient {
+func NewSentryClient(baseURL, authToken, org string, opts ClientOptions) *SentryClient {
 	if baseURL == "" {
 		baseURL = "https://sentry.io"
 	}
+	proxy := http.ProxyFromEnvironment
+	if opts.ProxyURL != "" {
+		// An invalid proxy fails every request rather than silently
+		// falling back to the environment's proxy
+		u, err := parseProxyURL(opts.ProxyURL)
+		proxy = func(*http.Request) (*url.URL, error) { return u, err }
+	}
 	return &SentryClient{
-		baseURL:   baseURL,
-		authToken: authToken,
-		org:       org,
+		baseURL:     baseURL,
+		fallbackURL: opts.FallbackURL,
+		authToken:   authToken,
+		org:         org,
+		timeouts:    opts.Timeouts,
+		retry:       opts.Retry,
+		limiter:     newRateLimiter(opts.RateLimit),
 		httpClient: &http.Client{
-			Timeout: defaultTimeout,
 			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+				Proxy: proxy,
+				TLSClientConfig: &tls.Config{
+					MinVersion:         tls.VersionTLS12,
+					ServerName:         opts.TLSServerName,
+					RootCAs:            opts.RootCAs,
+					InsecureSkipVerify: opts.InsecureSkipVerify,
+				},
 			},
 		},
 	}
 }
 
+// parseProxyURL parses a proxy URL, which must have a host and an http,
+// https or socks5 scheme.
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	switch {
+	case err != nil || u.Host == "":
+		return nil, fmt.Errorf("proxy URL must be a valid URL")
+	case u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5":
+		return nil, fmt.Errorf("invalid proxy URL scheme %q: must be http, https or socks5", u.Scheme)
+	}
+	return u, nil
+}
+
 // Release represents a Sentry release.
 type Release struct {
 	Version      string    `json:"version"`
@@ -49,6 +140,7 @@ type Release struct {
 	DateCreated  time.Time `json:"dateCreated,omitempty"`
 	DateReleased time.Time `json:"dateReleased,omitempty"`
 	Projects     []Project `json:"projects,omitempty"`
+	LastDeploy   *Deploy   `json:"lastDeploy,omitempty"`
 }
 
 // Project represents a Sentry project.
@@ -58,6 +150,51 @@ type Project struct {
 	Slug string `json:"slug"`
 }
 
+// Repository represents a repository registered with the organization's
+// Sentry integrations.
+type Repository struct {
+	ID       string             `json:"id"`
+	Name     string             `json:"name"`
+	URL      string             `json:"url,omitempty"`
+	Provider RepositoryProvider `json:"provider"`
+	// ExternalSlug is the repository's identifier at the provider, e.g.
+	// "acme/api" on GitHub or a numeric project ID on GitLab.
+	ExternalSlug string `json:"externalSlug,omitempty"`
+}
+
+// RepositoryProvider identifies the integration a repository is registered
+// through, e.g. {"id": "integrations:github", "name": "GitHub"}.
+type RepositoryProvider struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UnmarshalJSON decodes a repository, accepting a numeric externalSlug.
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	type repository Repository
+	aux := struct {
+		*repository
+		ExternalSlug any `json:"externalSlug"`
+	}{repository: (*repository)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	switch slug := aux.ExternalSlug.(type) {
+	case string:
+		r.ExternalSlug = slug
+	case float64:
+		r.ExternalSlug = strconv.FormatFloat(slug, 'f', -1, 64)
+	}
+	return nil
+}
+
+// Environment represents a Sentry project environment.
+type Environment struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	IsHidden bool   `json:"isHidden"`
+}
+
 // Deploy represents a Sentry deploy.
 type Deploy struct {
 	ID           string    `json:"id"`
@@ -67,6 +204,15 @@ type Deploy struct {
 	DateFinished time.Time `json:"dateFinished,omitempty"`
 }
 
+// ReleaseFile represents a file (artifact) attached to a Sentry release.
+type ReleaseFile struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	SHA1        string    `json:"sha1,omitempty"`
+	DateCreated time.Time `json:"dateCreated"`
+}
+
 // Organization represents a Sentry organization.
 type Organization struct {
 	ID   string `json:"id"`
@@ -86,16 +232,35 @@ type CommitSpec struct {
 
 // CreateReleaseRequest represents the request to create a release.
 type CreateReleaseRequest struct {
-	Version     string   `json:"version"`
-	Ref         string   `json:"ref,omitempty"`
-	URL         string   `json:"url,omitempty"`
-	Projects    []string `json:"projects"`
-	DateStarted string   `json:"dateStarted,omitempty"`
+	Version     string            `json:"version"`
+	Ref         string            `json:"ref,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Projects    []string          `json:"projects"`
+	DateStarted string            `json:"dateStarted,omitempty"`
+	VersionInfo map[string]string `json:"versionInfo,omitempty"`
+}
+
+// ReleaseOptions contains optional settings applied when creating a release.
+type ReleaseOptions struct {
+	Ref         string
+	URL         string
+	VersionInfo map[string]string
+	// QueryParams are appended, URL-encoded, to the request URL.
+	QueryParams map[string]string
+}
+
+// CommitRef identifies a commit range in a repository for Sentry to resolve.
+type CommitRef struct {
+	Repository     string `json:"repository"`
+	Commit         string `json:"commit"`
+	PreviousCommit string `json:"previousCommit,omitempty"`
 }
 
 // SetCommitsRequest represents the request to set commits.
 type SetCommitsRequest struct {
-	Commits []CommitSpec `json:"commits"`
+	Commits []CommitSpec `json:"commits,omitempty"`
+	Refs    []CommitRef  `json:"refs,omitempty"`
+	Auto    bool         `json:"auto,omitempty"`
 }
 
 // APIError represents a Sentry API error.
@@ -103,82 +268,349 @@ type APIError struct {
 	Detail string `json:"detail"`
 }
 
-// request makes an HTTP request to the Sentry API.
-func (c *SentryClient) request(ctx context.Context, method, endpoint string, body any, result any) error {
-	var reqBody io.Reader
+// Errors matched by StatusError for the statuses callers act on, e.g.
+// errors.Is(err, ErrUnauthorized).
+var (
+	// ErrUnauthorized matches 401 responses: the auth token is invalid,
+	// expired, or revoked.
+	ErrUnauthorized = errors.New("sentry: unauthorized")
+	// ErrForbidden matches 403 responses: the auth token lacks a scope or
+	// access to the organization or project.
+	ErrForbidden = errors.New("sentry: forbidden")
+	// ErrNotFound matches 404 responses.
+	ErrNotFound = errors.New("sentry: not found")
+)
+
+// StatusError is returned when the Sentry API responds with an error status.
+type StatusError struct {
+	StatusCode int
+	// Detail is the error reported by Sentry in the response body.
+	Detail  string
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// Is reports whether the error's status matches ErrUnauthorized,
+// ErrForbidden, or ErrNotFound.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// RateLimitError is returned when the Sentry API responds with 429 Too Many
+// Requests. It unwraps to the StatusError.
+type RateLimitError struct {
+	StatusError
+	// RetryAfter is how long Sentry asked to wait before retrying, from the
+	// Retry-After header; zero when the header was missing.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return &e.StatusError
+}
+
+// isNotFound reports whether err is a 404 response from the Sentry API.
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// isRateLimited reports whether err is a 429 response from the Sentry API.
+func isRateLimited(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests
+}
+
+// endpointScopes maps API endpoint fragments to the token scope they require.
+// Entries are checked in order, so more specific fragments come first.
+var endpointScopes = []struct {
+	fragment string
+	scope    string
+}{
+	{"/releases/", "project:releases"},
+	{"/projects/", "project:read"},
+	{"/organizations/", "org:read"},
+}
+
+// requiredScope returns the token scope needed for an endpoint, if known.
+func requiredScope(endpoint string) string {
+	for _, es := range endpointScopes {
+		if strings.Contains(endpoint, es.fragment) {
+			return es.scope
+		}
+	}
+	return ""
+}
+
+// parseAPIError extracts a readable message from a Sentry error body.
+// Sentry reports either {"detail": "..."} or field-keyed validation errors
+// such as {"version": ["is invalid"]}; anything else is returned verbatim.
+func parseAPIError(body []byte) string {
+	var apiErr APIError
+	if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Detail != "" {
+		return apiErr.Detail
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err == nil && len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var parts []string
+		for _, k := range keys {
+			if msgs := fieldMessages(fields[k]); len(msgs) > 0 {
+				parts = append(parts, fmt.Sprintf("%s: %s", k, strings.Join(msgs, ", ")))
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, "; ")
+		}
+	}
+
+	return string(body)
+}
+
+// fieldMessages returns the messages for a single field-level error value.
+func fieldMessages(v any) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []any:
+		var msgs []string
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				msgs = append(msgs, s)
+			}
+		}
+		return msgs
+	}
+	return nil
+}
+
+// Timeout categories group endpoints with similar latency so their timeouts
+// can be tuned independently.
+const (
+	timeoutMetadata = "metadata"
+	timeoutCommits  = "commits"
+	timeoutDeploys  = "deploys"
+	timeoutFinalize = "finalize"
+	timeoutFiles    = "files"
+)
+
+// timeoutCategories lists the valid timeout categories.
+var timeoutCategories = []string{timeoutMetadata, timeoutCommits, timeoutDeploys, timeoutFinalize, timeoutFiles}
+
+// timeout returns the request timeout for an endpoint category.
+func (c *SentryClient) timeout(category string) time.Duration {
+	if d, ok := c.timeouts[category]; ok && d > 0 {
+		return d
+	}
+	return defaultTimeout
+}
+
+// request makes an HTTP request to the Sentry API, bounded by the timeout
+// for the endpoint category.
+func (c *SentryClient) request(ctx context.Context, category, method, endpoint string, body any, result any) error {
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
+		var err error
+		if jsonBody, err = json.Marshal(body); err != nil {
 			return fmt.Errorf("failed to marshal request: %w", err)
 		}
-		reqBody = bytes.NewReader(jsonBody)
 	}
+	return c.requestRaw(ctx, category, method, endpoint, jsonBody, "application/json", result)
+}
 
-	fullURL := c.baseURL + "/api/0" + endpoint
+// requestRaw makes an HTTP request with an already encoded body of the
+// given content type, retrying retryable error responses.
+func (c *SentryClient) requestRaw(ctx context.Context, category, method, endpoint string, body []byte, contentType string, result any) error {
+	_, err := c.requestWithHeader(ctx, category, method, endpoint, body, contentType, result)
+	return err
+}
+
+// requestWithHeader is requestRaw, also returning the response headers of
+// the successful attempt. Requests that cannot reach the primary Sentry,
+// including ones that time out, are sent to the fallback URL, when one is
+// configured, whose attempts get timeouts of their own.
+func (c *SentryClient) requestWithHeader(ctx context.Context, category, method, endpoint string, body []byte, contentType string, result any) (http.Header, error) {
+	timeout := c.timeout(category)
+	header, err := c.attempt(ctx, c.baseURL, timeout, method, endpoint, body, contentType, result)
+	var urlErr *url.Error
+	if c.fallbackURL != "" && errors.As(err, &urlErr) && ctx.Err() == nil {
+		header, err = c.attempt(ctx, c.fallbackURL, timeout, method, endpoint, body, contentType, result)
+	}
+	return header, err
+}
+
+// attempt sends a request to one Sentry base URL. Idempotent requests that
+// fail with a retryable error response or connection error are retried
+// with exponential backoff, and rate-limited requests of any method after
+// the Retry-After delay. Each try is bounded by timeout on its own and
+// first waits for the client's rate limit, which the timeout excludes.
+func (c *SentryClient) attempt(ctx context.Context, baseURL string, timeout time.Duration, method, endpoint string, body []byte, contentType string, result any) (http.Header, error) {
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, fmt.Errorf("waiting for rate limit: %w", err)
+		}
+		tryCtx, cancel := context.WithTimeout(ctx, timeout)
+		header, err := c.send(tryCtx, baseURL, method, endpoint, body, contentType, result)
+		cancel()
+		if err == nil || attempt >= c.retry.maxAttempts() || !c.retry.retriesRequest(method, err) || !c.retry.retryableError(err) {
+			return header, err
+		}
+		// An unreachable primary fails over at once rather than being retried
+		var urlErr *url.Error
+		if c.fallbackURL != "" && baseURL != c.fallbackURL && errors.As(err, &urlErr) {
+			return header, err
+		}
+		delay := retryDelay(attempt)
+		var rateErr *RateLimitError
+		if errors.As(err, &rateErr) && rateErr.RetryAfter > 0 {
+			delay = rateErr.RetryAfter
+		}
+		if sleepBackoff(ctx, delay) != nil {
+			return nil, err
+		}
+	}
+}
+
+// send performs a single attempt of an API request against baseURL,
+// returning the response headers.
+func (c *SentryClient) send(ctx context.Context, baseURL, method, endpoint string, body []byte, contentType string, result any) (http.Header, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	fullURL := baseURL + "/api/0" + endpoint
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.authToken)
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 
+	start := time.Now()
+	defer func() { c.apiTime.add(time.Since(start)) }()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		c.debug.logRequest(req, 0, nil, time.Since(start), err)
+		c.metrics.add(metricAPIErrors, 1)
+		c.audit.record(auditEntry{Timestamp: start.UTC(), Method: method, Endpoint: endpoint, Server: c.auditServer(baseURL), DurationMS: time.Since(start).Milliseconds(), Error: err.Error()})
+		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	c.audit.record(auditEntry{Timestamp: start.UTC(), Method: method, Endpoint: endpoint, Server: c.auditServer(baseURL), Status: resp.StatusCode, DurationMS: time.Since(start).Milliseconds()})
 
 	respBody, err := io.ReadAll(resp.Body)
+	c.debug.logRequest(req, resp.StatusCode, respBody, time.Since(start), err)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		var apiErr APIError
-		if err := json.Unmarshal(respBody, &apiErr); err == nil && apiErr.Detail != "" {
-			return fmt.Errorf("API error: %s (status %d)", apiErr.Detail, resp.StatusCode)
-		}
-		return fmt.Errorf("API error: %s (status %d)", string(respBody), resp.StatusCode)
+		c.metrics.add(metricAPIErrors, 1)
+		return nil, newStatusError(resp, endpoint, respBody)
 	}
 
 	if result != nil && len(respBody) > 0 {
 		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 		}
 	}
 
-	return nil
+	return resp.Header, nil
+}
+
+// newStatusError builds the error for an error response, telling the user
+// how to fix authentication and scope failures.
+func newStatusError(resp *http.Response, endpoint string, body []byte) error {
+	detail := parseAPIError(body)
+	statusErr := StatusError{
+		StatusCode: resp.StatusCode,
+		Detail:     detail,
+		Message:    fmt.Sprintf("API error: %s (status %d)", detail, resp.StatusCode),
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		statusErr.Message += ": the auth token is invalid, expired, or revoked; check SENTRY_AUTH_TOKEN"
+	case http.StatusForbidden:
+		if scope := requiredScope(endpoint); scope != "" {
+			statusErr.Message += fmt.Sprintf(": token lacks '%s' scope; create a token with this scope under Sentry Settings > Auth Tokens", scope)
+		}
+	case http.StatusTooManyRequests:
+		statusErr.Message = fmt.Sprintf("API error: %s (status %d, rate limited)", detail, resp.StatusCode)
+		return &RateLimitError{
+			StatusError: statusErr,
+			RetryAfter:  parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return &statusErr
+}
+
+// auditServer returns the base URL to record with an audit entry. It is
+// only recorded when a fallback URL could have served the request.
+func (c *SentryClient) auditServer(baseURL string) string {
+	if c.fallbackURL == "" {
+		return ""
+	}
+	return baseURL
 }
 
 // GetOrganization gets the configured organization.
 func (c *SentryClient) GetOrganization(ctx context.Context) (*Organization, error) {
 	endpoint := fmt.Sprintf("/organizations/%s/", c.org)
 	var org Organization
-	if err := c.request(ctx, http.MethodGet, endpoint, nil, &org); err != nil {
+	if err := c.request(ctx, timeoutMetadata, http.MethodGet, endpoint, nil, &org); err != nil {
 		return nil, err
 	}
 	return &org, nil
 }
 
 // CreateRelease creates a new release in Sentry.
-func (c *SentryClient) CreateRelease(ctx context.Context, version string, projects []string) (*Release, error) {
+func (c *SentryClient) CreateRelease(ctx context.Context, version string, projects []string, opts ReleaseOptions) (*Release, error) {
 	endpoint := fmt.Sprintf("/organizations/%s/releases/", c.org)
+	if len(opts.QueryParams) > 0 {
+		query := url.Values{}
+		for k, v := range opts.QueryParams {
+			query.Set(k, v)
+		}
+		endpoint += "?" + query.Encode()
+	}
 
 	req := CreateReleaseRequest{
 		Version:     version,
+		Ref:         opts.Ref,
+		URL:         opts.URL,
 		Projects:    projects,
 		DateStarted: time.Now().UTC().Format(time.RFC3339),
+		VersionInfo: opts.VersionInfo,
 	}
 
 	var release Release
-	if err := c.request(ctx, http.MethodPost, endpoint, req, &release); err != nil {
+	if err := c.request(ctx, timeoutMetadata, http.MethodPost, endpoint, req, &release); err != nil {
 		// Check if release already exists
 		if existingRelease, getErr := c.GetRelease(ctx, version); getErr == nil {
 			return existingRelease, nil
 		}
 		return nil, err
 	}
+	c.metrics.add(metricReleasesCreated, 1)
 	return &release, nil
 }
 
@@ -186,17 +618,106 @@ func (c *SentryClient) CreateRelease(ctx context.Context, version string, projec
 func (c *SentryClient) GetRelease(ctx context.Context, version string) (*Release, error) {
 	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/", c.org, url.PathEscape(version))
 	var release Release
-	if err := c.request(ctx, http.MethodGet, endpoint, nil, &release); err != nil {
+	if err := c.request(ctx, timeoutMetadata, http.MethodGet, endpoint, nil, &release); err != nil {
 		return nil, err
 	}
 	return &release, nil
 }
 
-// SetCommits associates commits with a release.
-func (c *SentryClient) SetCommits(ctx context.Context, version string, commits []CommitSpec) error {
+// ListReleases lists the organization's releases, following pagination
+// until every page has been read.
+func (c *SentryClient) ListReleases(ctx context.Context, opts ListOptions) ([]Release, error) {
+	endpoint := fmt.Sprintf("/organizations/%s/releases/%s", c.org, opts.encode())
+	return listAll[Release](ctx, c, timeoutMetadata, endpoint)
+}
+
+// DeleteRelease deletes a release. Deleting a release that does not exist
+// succeeds, so cleanups can be repeated.
+func (c *SentryClient) DeleteRelease(ctx context.Context, version string) error {
+	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/", c.org, url.PathEscape(version))
+	err := c.request(ctx, timeoutMetadata, http.MethodDelete, endpoint, nil, nil)
+	var statusErr *StatusError
+	switch {
+	case isNotFound(err):
+		return nil
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict:
+		return fmt.Errorf("release %s has events or files associated with it and cannot be deleted: %w", version, err)
+	}
+	return err
+}
+
+// ArchiveRelease archives a release, hiding it from the release list and
+// from release health.
+func (c *SentryClient) ArchiveRelease(ctx context.Context, version string) error {
+	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/", c.org, url.PathEscape(version))
+	return c.request(ctx, timeoutMetadata, http.MethodPut, endpoint, map[string]string{"status": "archived"}, nil)
+}
+
+// SetCommits associates commits with a release. It returns the IDs of the
+// commits that Sentry did not report back as associated; when the response
+// does not list commits, all commits are assumed to be associated.
+func (c *SentryClient) SetCommits(ctx context.Context, version string, commits []CommitSpec) ([]string, error) {
 	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/commits/", c.org, url.PathEscape(version))
 	req := SetCommitsRequest{Commits: commits}
-	return c.request(ctx, http.MethodPost, endpoint, req, nil)
+
+	var resp json.RawMessage
+	if err := c.request(ctx, timeoutCommits, http.MethodPost, endpoint, req, &resp); err != nil {
+		return nil, err
+	}
+
+	var associated []struct {
+		ID string `json:"id"`
+	}
+	if len(resp) == 0 || json.Unmarshal(resp, &associated) != nil {
+		return nil, nil
+	}
+
+	found := make(map[string]bool, len(associated))
+	for _, a := range associated {
+		found[a.ID] = true
+	}
+
+	var unassociated []string
+	for _, commit := range commits {
+		if !found[commit.ID] {
+			unassociated = append(unassociated, commit.ID)
+		}
+	}
+	return unassociated, nil
+}
+
+// SetCommitRefs associates the commits in the given ranges with a release,
+// letting Sentry resolve the commits from its repository integration.
+func (c *SentryClient) SetCommitRefs(ctx context.Context, version string, refs []CommitRef) error {
+	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/commits/", c.org, url.PathEscape(version))
+	return c.request(ctx, timeoutCommits, http.MethodPost, endpoint, SetCommitsRequest{Refs: refs}, nil)
+}
+
+// SetCommitsAuto lets Sentry determine a release's commits from its
+// repository integrations, since the previous release. Refs optionally pin
+// the head commit of a repository.
+func (c *SentryClient) SetCommitsAuto(ctx context.Context, version string, refs ...CommitRef) error {
+	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/commits/", c.org, url.PathEscape(version))
+	return c.request(ctx, timeoutCommits, http.MethodPost, endpoint, SetCommitsRequest{Refs: refs, Auto: true}, nil)
+}
+
+// GetLatestDeployedRelease returns the most recent release deployed to an
+// environment, other than excludeVersion, or nil if there is none.
+func (c *SentryClient) GetLatestDeployedRelease(ctx context.Context, environment, excludeVersion string) (*Release, error) {
+	query := url.Values{}
+	query.Set("environment", environment)
+	endpoint := fmt.Sprintf("/organizations/%s/releases/?%s", c.org, query.Encode())
+
+	var releases []Release
+	if err := c.request(ctx, timeoutMetadata, http.MethodGet, endpoint, nil, &releases); err != nil {
+		return nil, err
+	}
+	for i := range releases {
+		if releases[i].Version != excludeVersion && releases[i].LastDeploy != nil {
+			return &releases[i], nil
+		}
+	}
+	return nil, nil
 }
 
 // CreateDeploy creates a deploy record for a release.
@@ -208,32 +729,179 @@ func (c *SentryClient) CreateDeploy(ctx context.Context, version string, deploy
 		"dateStarted":  time.Now().UTC().Format(time.RFC3339),
 		"dateFinished": time.Now().UTC().Format(time.RFC3339),
 	}
-	if deploy.Name != "" {
-		req["name"] = deploy.Name
+	if name := deploy.displayName(); name != "" {
+		req["name"] = name
 	}
 
 	var result Deploy
-	if err := c.request(ctx, http.MethodPost, endpoint, req, &result); err != nil {
+	if err := c.request(ctx, timeoutDeploys, http.MethodPost, endpoint, req, &result); err != nil {
 		return nil, err
 	}
+	c.metrics.add(metricDeploysCreated, 1)
 	return &result, nil
 }
 
+// DeleteDeploy deletes a deploy record from a release. Deleting a deploy
+// that does not exist succeeds, so the call is idempotent.
+func (c *SentryClient) DeleteDeploy(ctx context.Context, version, deployID string) error {
+	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/deploys/%s/", c.org, url.PathEscape(version), url.PathEscape(deployID))
+	if err := c.request(ctx, timeoutDeploys, http.MethodDelete, endpoint, nil, nil); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 // FinalizeRelease marks a release as finalized.
 func (c *SentryClient) FinalizeRelease(ctx context.Context, version string) error {
 	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/", c.org, url.PathEscape(version))
 	req := map[string]any{
 		"dateReleased": time.Now().UTC().Format(time.RFC3339),
 	}
-	return c.request(ctx, http.MethodPut, endpoint, req, nil)
+	return c.request(ctx, timeoutFinalize, http.MethodPut, endpoint, req, nil)
 }
 
 // GetProject gets project details.
 func (c *SentryClient) GetProject(ctx context.Context, projectSlug string) (*Project, error) {
 	endpoint := fmt.Sprintf("/projects/%s/%s/", c.org, projectSlug)
 	var project Project
-	if err := c.request(ctx, http.MethodGet, endpoint, nil, &project); err != nil {
+	if err := c.request(ctx, timeoutMetadata, http.MethodGet, endpoint, nil, &project); err != nil {
 		return nil, err
 	}
 	return &project, nil
 }
+
+// ListRepositories lists every repository registered with the organization,
+// following pagination.
+func (c *SentryClient) ListRepositories(ctx context.Context) ([]Repository, error) {
+	endpoint := fmt.Sprintf("/organizations/%s/repos/", c.org)
+	return listAll[Repository](ctx, c, timeoutMetadata, endpoint)
+}
+
+// ListEnvironments lists the environments Sentry has seen for a project.
+func (c *SentryClient) ListEnvironments(ctx context.Context, projectSlug string) ([]Environment, error) {
+	endpoint := fmt.Sprintf("/projects/%s/%s/environments/", c.org, url.PathEscape(projectSlug))
+	var environments []Environment
+	if err := c.request(ctx, timeoutMetadata, http.MethodGet, endpoint, nil, &environments); err != nil {
+		return nil, err
+	}
+	return environments, nil
+}
+
+// ListReleaseFiles lists the files attached to a release, following
+// pagination. A non-empty prefix, such as "~/static/", is passed to Sentry
+// as a search query so that releases with many artifacts only return
+// metadata for matching files; only names starting with prefix are kept.
+func (c *SentryClient) ListReleaseFiles(ctx context.Context, version, prefix string) ([]ReleaseFile, error) {
+	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/files/", c.org, url.PathEscape(version))
+	if prefix != "" {
+		endpoint += "?query=" + url.QueryEscape(prefix)
+	}
+	files, err := listAll[ReleaseFile](ctx, c, timeoutFiles, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if prefix == "" {
+		return files, nil
+	}
+
+	matching := files[:0]
+	for _, f := range files {
+		if strings.HasPrefix(f.Name, prefix) {
+			matching = append(matching, f)
+		}
+	}
+	return matching, nil
+}
+
+// UploadReleaseFile uploads a file to a release under name, e.g.
+// "~/static/app.js.map". The multipart file part carries contentType so
+// Sentry classifies the artifact correctly.
+func (c *SentryClient) UploadReleaseFile(ctx context.Context, version, name string, content []byte, contentType string) (*ReleaseFile, error) {
+	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/files/", c.org, url.PathEscape(version))
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("name", name); err != nil {
+		return nil, fmt.Errorf("failed to encode upload: %w", err)
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, path.Base(name)))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode upload: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to encode upload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode upload: %w", err)
+	}
+
+	var file ReleaseFile
+	if err := c.requestRaw(ctx, timeoutFiles, http.MethodPost, endpoint, body.Bytes(), writer.FormDataContentType(), &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// UploadSourcemaps uploads the files under cfg.Path selected by the
+// include and exclude globs to a release, naming each with cfg.URLPrefix
+// prepended. It stops at the first failed upload, returning the files
+// uploaded so far.
+func (c *SentryClient) UploadSourcemaps(ctx context.Context, version string, cfg SourcemapsConfig) ([]ReleaseFile, error) {
+	files, err := sourcemapFiles(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var uploaded []ReleaseFile
+	for _, rel := range files {
+		content, err := os.ReadFile(filepath.Join(cfg.Path, filepath.FromSlash(rel)))
+		if err != nil {
+			return uploaded, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		name := releaseFileName(cfg.URLPrefix, rel)
+		file, err := c.UploadReleaseFile(ctx, version, name, content, releaseFileContentType(name, cfg.ContentTypes))
+		if err != nil {
+			return uploaded, fmt.Errorf("failed to upload %s: %w", name, err)
+		}
+		uploaded = append(uploaded, *file)
+	}
+	return uploaded, nil
+}
+
+// DeleteReleaseFile deletes a file from a release.
+func (c *SentryClient) DeleteReleaseFile(ctx context.Context, version, fileID string) error {
+	endpoint := fmt.Sprintf("/organizations/%s/releases/%s/files/%s/", c.org, url.PathEscape(version), url.PathEscape(fileID))
+	return c.request(ctx, timeoutFiles, http.MethodDelete, endpoint, nil, nil)
+}
+
+// Team represents a Sentry team.
+type Team struct {
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// ListProjects lists the projects in the organization.
+func (c *SentryClient) ListProjects(ctx context.Context) ([]Project, error) {
+	endpoint := fmt.Sprintf("/organizations/%s/projects/", c.org)
+	return listAll[Project](ctx, c, timeoutMetadata, endpoint)
+}
+
+// GetTeam gets team details.
+func (c *SentryClient) GetTeam(ctx context.Context, teamSlug string) (*Team, error) {
+	endpoint := fmt.Sprintf("/teams/%s/%s/", c.org, url.PathEscape(teamSlug))
+	var team Team
+	if err := c.request(ctx, timeoutMetadata, http.MethodGet, endpoint, nil, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// ListTeamProjects lists the projects belonging to a team.
+func (c *SentryClient) ListTeamProjects(ctx context.Context, teamSlug string) ([]Project, error) {
+	endpoint := fmt.Sprintf("/teams/%s/%s/projects/", c.org, url.PathEscape(teamSlug))
+	return listAll[Project](ctx, c, timeoutMetadata, endpoint)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// debugBodyLimit is how many bytes of a response body are logged.
+const debugBodyLimit = 1024
+
+// debugOutput receives the debug log. The plugin SDK has no logger, and the
+// host captures the plugin's stderr.
+var debugOutput io.Writer = os.Stderr
+
+// debugLogger logs Sentry API requests and responses for the debug option.
+// A nil *debugLogger logs nothing.
+type debugLogger struct {
+	logger *slog.Logger
+	token  string
+}
+
+func newDebugLogger(w io.Writer, token string) *debugLogger {
+	return &debugLogger{
+		logger: slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		token:  token,
+	}
+}
+
+// logRequest logs a completed API call: the request with its headers, and
+// either the response status and truncated body or the error. The
+// Authorization header and any occurrence of the auth token are redacted.
+func (l *debugLogger) logRequest(req *http.Request, status int, body []byte, duration time.Duration, err error) {
+	if l == nil {
+		return
+	}
+	headers := make([]any, 0, len(req.Header))
+	for _, name := range slices.Sorted(maps.Keys(req.Header)) {
+		value := strings.Join(req.Header[name], ", ")
+		if name == "Authorization" {
+			value = "[REDACTED]"
+		}
+		headers = append(headers, slog.String(name, l.redact(value)))
+	}
+	attrs := []any{
+		slog.String("method", req.Method),
+		slog.String("url", l.redact(req.URL.String())),
+		slog.Group("headers", headers...),
+		slog.Int64("duration_ms", duration.Milliseconds()),
+	}
+	if err != nil {
+		l.logger.Debug("sentry api request failed", append(attrs, slog.String("error", l.redact(err.Error())))...)
+		return
+	}
+	l.logger.Debug("sentry api request", append(attrs, slog.Int("status", status), slog.String("body", l.redact(truncateBody(body))))...)
+}
+
+// logFanOut logs the effective spread between the first and last start of
+// a fanned-out operation, to show how fan_out_stagger smoothed it out.
+func (l *debugLogger) logFanOut(operation string, calls int, spread time.Duration) {
+	if l == nil {
+		return
+	}
+	l.logger.Debug("sentry fan-out", slog.String("operation", operation), slog.Int("calls", calls), slog.Int64("spread_ms", spread.Milliseconds()))
+}
+
+// redact replaces the auth token in s.
+func (l *debugLogger) redact(s string) string {
+	if l.token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, l.token, "[REDACTED]")
+}
+
+// truncateBody returns body as text, cut to debugBodyLimit bytes.
+func truncateBody(body []byte) string {
+	if len(body) <= debugBodyLimit {
+		return string(body)
+	}
+	return string(body[:debugBodyLimit]) + "... (truncated)"
+}
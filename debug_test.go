@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExecuteDebugLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": "1.0.0", "token": "secret-token"}`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	saved := debugOutput
+	debugOutput = &out
+	defer func() { debugOutput = saved }()
+
+	run := func(debug bool) {
+		t.Helper()
+		p := &SentryPlugin{}
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookPostPublish,
+			Config: map[string]any{
+				"auth_token":    "secret-token",
+				"org":           "my-org",
+				"project":       "my-project",
+				"url":           server.URL,
+				"set_commits":   false,
+				"create_deploy": false,
+				"debug":         debug,
+			},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+		})
+		if err != nil || !resp.Success {
+			t.Fatalf("Execute() error = %v, %+v", err, resp)
+		}
+	}
+
+	run(false)
+	if out.Len() != 0 {
+		t.Fatalf("expected no debug output by default, got: %s", out.String())
+	}
+
+	run(true)
+	log := out.String()
+	for _, want := range []string{"method=PUT", "url=" + server.URL + "/api/0/organizations/my-org/releases/1.0.0/", "status=200", "headers.Authorization=[REDACTED]", `\"version\": \"1.0.0\"`} {
+		if !strings.Contains(log, want) {
+			t.Errorf("expected debug log to contain %q, got: %s", want, log)
+		}
+	}
+	if strings.Contains(log, "secret-token") {
+		t.Errorf("expected the auth token to be redacted, got: %s", log)
+	}
+}
+
+func TestTruncateBody(t *testing.T) {
+	if got := truncateBody([]byte("short")); got != "short" {
+		t.Errorf("truncateBody() = %q, want %q", got, "short")
+	}
+	long := bytes.Repeat([]byte("x"), debugBodyLimit+10)
+	if got := truncateBody(long); len(got) != debugBodyLimit+len("... (truncated)") || !strings.HasSuffix(got, "... (truncated)") {
+		t.Errorf("expected body truncated to %d bytes, got %d", debugBodyLimit, len(got))
+	}
+}
+
+func TestDebugLoggerFanOut(t *testing.T) {
+	var out bytes.Buffer
+	newDebugLogger(&out, "").logFanOut("create releases", 3, 1500*time.Millisecond)
+	for _, want := range []string{`operation="create releases"`, "calls=3", "spread_ms=1500"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected fan-out log to contain %q, got: %s", want, out.String())
+		}
+	}
+
+	// A nil logger, with debug off, logs nothing
+	var off *debugLogger
+	off.logFanOut("create releases", 3, time.Second)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// deprecatedKeys maps renamed config keys to their replacements. Dotted keys
+// refer to nested sections, e.g. "commits.auto". A deprecated key is still
+// honored when its replacement is not set. No key has been renamed yet.
+var deprecatedKeys = map[string]string{}
+
+// migrateDeprecatedKeys returns raw with the values of deprecated keys moved
+// to their replacements, and the deprecated keys that were set, sorted.
+// raw itself is not modified.
+func migrateDeprecatedKeys(raw map[string]any) (map[string]any, []string) {
+	var found []string
+	for old := range deprecatedKeys {
+		if _, ok := lookupKey(raw, old); ok {
+			found = append(found, old)
+		}
+	}
+	if len(found) == 0 {
+		return raw, nil
+	}
+	sort.Strings(found)
+
+	migrated := copyConfigMap(raw)
+	for _, old := range found {
+		replacement := deprecatedKeys[old]
+		if _, ok := lookupKey(migrated, replacement); ok {
+			continue
+		}
+		v, _ := lookupKey(migrated, old)
+		setKey(migrated, replacement, v)
+	}
+	return migrated, found
+}
+
+// deprecationMessage describes a deprecated key and its replacement.
+func deprecationMessage(old string) string {
+	return fmt.Sprintf("Config key %q is deprecated; use %q instead", old, deprecatedKeys[old])
+}
+
+// deprecationWarnings returns validation warnings for deprecated keys.
+func deprecationWarnings(keys []string) []plugin.ValidationError {
+	var warnings []plugin.ValidationError
+	for _, key := range keys {
+		warnings = append(warnings, plugin.ValidationError{
+			Field:   key,
+			Message: deprecationMessage(key),
+			Code:    validationWarningCode,
+		})
+	}
+	return warnings
+}
+
+// lookupKey returns the value at a dotted key.
+func lookupKey(raw map[string]any, key string) (any, bool) {
+	section, rest, nested := strings.Cut(key, ".")
+	if !nested {
+		v, ok := raw[key]
+		return v, ok && v != nil
+	}
+	sub, ok := raw[section].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	return lookupKey(sub, rest)
+}
+
+// setKey sets the value at a dotted key, creating nested sections as
+// needed. Nested sections are copied before they are modified.
+func setKey(raw map[string]any, key string, v any) {
+	section, rest, nested := strings.Cut(key, ".")
+	if !nested {
+		raw[key] = v
+		return
+	}
+	sub, _ := raw[section].(map[string]any)
+	sub = copyConfigMap(sub)
+	setKey(sub, rest, v)
+	raw[section] = sub
+}
+
+// copyConfigMap returns a shallow copy of a config map.
+func copyConfigMap(raw map[string]any) map[string]any {
+	copied := make(map[string]any, len(raw)+1)
+	for k, v := range raw {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func withDeprecatedKeys(t *testing.T, keys map[string]string) {
+	t.Helper()
+	saved := deprecatedKeys
+	deprecatedKeys = keys
+	t.Cleanup(func() { deprecatedKeys = saved })
+}
+
+func TestMigrateDeprecatedKeys(t *testing.T) {
+	withDeprecatedKeys(t, map[string]string{
+		"sentry_env":    "environment",
+		"commits.repo":  "commits.repository",
+		"old_summary":   "summary",
+		"deploy_envs":   "deploy.environments",
+		"unused_legacy": "finalize",
+	})
+
+	raw := map[string]any{
+		"sentry_env":  "staging",
+		"commits":     map[string]any{"repo": "org/old", "auto": true},
+		"old_summary": true,
+		"summary":     false,
+		"deploy_envs": []any{"staging"},
+	}
+	migrated, found := migrateDeprecatedKeys(raw)
+
+	if strings.Join(found, ",") != "commits.repo,deploy_envs,old_summary,sentry_env" {
+		t.Errorf("unexpected deprecated keys: %v", found)
+	}
+	if migrated["environment"] != "staging" {
+		t.Errorf("expected environment to be migrated, got %v", migrated["environment"])
+	}
+	if commits := migrated["commits"].(map[string]any); commits["repository"] != "org/old" || commits["auto"] != true {
+		t.Errorf("expected commits.repository to be migrated, got %v", commits)
+	}
+	if migrated["summary"] != false {
+		t.Error("expected the replacement key to take precedence")
+	}
+	if deploy, ok := migrated["deploy"].(map[string]any); !ok || deploy["environments"] == nil {
+		t.Errorf("expected deploy.environments to be created, got %v", migrated["deploy"])
+	}
+	if _, ok := raw["environment"]; ok {
+		t.Error("expected the original config to be left unmodified")
+	}
+	if _, ok := raw["commits"].(map[string]any)["repository"]; ok {
+		t.Error("expected the original nested config to be left unmodified")
+	}
+}
+
+func TestDeprecatedKeysWarnings(t *testing.T) {
+	withDeprecatedKeys(t, map[string]string{"sentry_env": "environment"})
+
+	config := map[string]any{
+		"auth_token": "test-token",
+		"org":        "my-org",
+		"project":    "my-project",
+		"sentry_env": "staging",
+	}
+
+	p := &SentryPlugin{}
+	if cfg := p.parseConfig(config); cfg.Environment != "staging" {
+		t.Errorf("expected deprecated key to be honored, got environment %q", cfg.Environment)
+	}
+
+	vresp, err := p.Validate(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	found := false
+	for _, e := range vresp.Errors {
+		if e.Field == "sentry_env" && e.Code == validationWarningCode && strings.Contains(e.Message, `use "environment"`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected deprecation warning, got %+v", vresp.Errors)
+	}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Config:  config,
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+		DryRun:  true,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(resp.Message, `Warning: Config key "sentry_env" is deprecated`) {
+		t.Errorf("expected deprecation warning in message, got: %s", resp.Message)
+	}
+}
+
+func TestValidateMigratesDeprecatedKeys(t *testing.T) {
+	withDeprecatedKeys(t, map[string]string{"stagger": "fan_out_stagger"})
+
+	p := &SentryPlugin{}
+	vresp, err := p.Validate(context.Background(), map[string]any{
+		"auth_token": "test-token",
+		"org":        "my-org",
+		"project":    "my-project",
+		"url":        "http://127.0.0.1:0",
+		"stagger":    "soon",
+	})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	found := false
+	for _, e := range vresp.Errors {
+		if e.Field == "fan_out_stagger" && e.Code != validationWarningCode {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the deprecated key's value to be validated, got %+v", vresp.Errors)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+// parseDuration parses a duration given either as a number of seconds
+// (30, "30") or as a Go duration string ("30s", "2m"). Negative durations
+// are rejected.
+func parseDuration(v any) (time.Duration, error) {
+	var d time.Duration
+	switch val := v.(type) {
+	case int:
+		d = time.Duration(val) * time.Second
+	case int64:
+		d = time.Duration(val) * time.Second
+	case float64:
+		d = time.Duration(val * float64(time.Second))
+	case string:
+		if secs, err := strconv.Atoi(val); err == nil {
+			d = time.Duration(secs) * time.Second
+		} else {
+			parsed, err := time.ParseDuration(val)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q: use seconds or a duration such as \"30s\" or \"2m\"", val)
+			}
+			d = parsed
+		}
+	default:
+		return 0, fmt.Errorf("invalid duration %v: use seconds or a duration such as \"30s\" or \"2m\"", v)
+	}
+
+	if d < 0 {
+		return 0, fmt.Errorf("invalid duration %v: must not be negative", v)
+	}
+	return d, nil
+}
+
+// parsePositiveDuration is parseDuration, also rejecting zero.
+func parsePositiveDuration(v any) (time.Duration, error) {
+	d, err := parseDuration(v)
+	if err == nil && d == 0 {
+		return 0, fmt.Errorf("invalid duration %v: must be greater than zero", v)
+	}
+	return d, err
+}
+
+// getDuration returns the duration at key, or defaultVal when the key is
+// missing or invalid. Invalid values are reported by validateDuration.
+func getDuration(raw map[string]any, key string, defaultVal time.Duration) time.Duration {
+	return durationAt(raw, key, defaultVal, parseDuration)
+}
+
+// getPositiveDuration is getDuration for durations that must be greater
+// than zero, such as a cutoff that would otherwise select everything.
+func getPositiveDuration(raw map[string]any, key string, defaultVal time.Duration) time.Duration {
+	return durationAt(raw, key, defaultVal, parsePositiveDuration)
+}
+
+// durationAt returns the duration at key parsed with parse, or defaultVal
+// when the key is missing or invalid.
+func durationAt(raw map[string]any, key string, defaultVal time.Duration, parse func(any) (time.Duration, error)) time.Duration {
+	v, ok := raw[key]
+	if !ok || v == nil || v == "" {
+		return defaultVal
+	}
+	d, err := parse(v)
+	if err != nil {
+		return defaultVal
+	}
+	return d
+}
+
+// validateDuration adds a validation error for field when key is set but
+// is not a valid duration.
+func validateDuration(vb *helpers.ValidationBuilder, raw map[string]any, key, field string) {
+	validateDurationWith(vb, raw, key, field, parseDuration)
+}
+
+// validatePositiveDuration is validateDuration for durations that must be
+// greater than zero.
+func validatePositiveDuration(vb *helpers.ValidationBuilder, raw map[string]any, key, field string) {
+	validateDurationWith(vb, raw, key, field, parsePositiveDuration)
+}
+
+// validateDurationWith reports an invalid duration at key, as judged by
+// parse, on field.
+func validateDurationWith(vb *helpers.ValidationBuilder, raw map[string]any, key, field string, parse func(any) (time.Duration, error)) {
+	v, ok := raw[key]
+	if !ok || v == nil || v == "" {
+		return
+	}
+	if _, err := parse(v); err != nil {
+		vb.AddError(field, fmt.Sprintf("Invalid %s: %v", field, err))
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"integer seconds", 30, 30 * time.Second, false},
+		{"float seconds", float64(1.5), 1500 * time.Millisecond, false},
+		{"numeric string", "45", 45 * time.Second, false},
+		{"duration string", "2m", 2 * time.Minute, false},
+		{"sub-second duration", "250ms", 250 * time.Millisecond, false},
+		{"invalid string", "soon", 0, true},
+		{"negative", "-5s", 0, true},
+		{"wrong type", true, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDuration(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("parseDuration(%v) = %s, want %s", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetDuration(t *testing.T) {
+	raw := map[string]any{"valid": "10s", "invalid": "soon"}
+
+	if got := getDuration(raw, "valid", time.Second); got != 10*time.Second {
+		t.Errorf("expected 10s, got %s", got)
+	}
+	if got := getDuration(raw, "invalid", time.Second); got != time.Second {
+		t.Errorf("expected default for invalid value, got %s", got)
+	}
+	if got := getDuration(raw, "missing", time.Second); got != time.Second {
+		t.Errorf("expected default for missing value, got %s", got)
+	}
+}
+
+func TestValidateDuration(t *testing.T) {
+	vb := helpers.NewValidationBuilder()
+	raw := map[string]any{"good": "1m", "bad": "soon"}
+
+	validateDuration(vb, raw, "good", "good")
+	if vb.HasErrors() {
+		t.Fatal("expected no errors for a valid duration")
+	}
+
+	validateDuration(vb, raw, "bad", "bad")
+	resp := vb.Build()
+	if len(resp.Errors) != 1 || resp.Errors[0].Field != "bad" {
+		t.Errorf("expected one error for field 'bad', got %v", resp.Errors)
+	}
+}
+
+func TestPositiveDuration(t *testing.T) {
+	raw := map[string]any{"zero": 0, "zero_string": "0s", "valid": "1h"}
+
+	if got := getPositiveDuration(raw, "zero", time.Hour); got != time.Hour {
+		t.Errorf("expected default for zero, got %s", got)
+	}
+	if got := getPositiveDuration(raw, "valid", time.Minute); got != time.Hour {
+		t.Errorf("expected 1h, got %s", got)
+	}
+
+	vb := helpers.NewValidationBuilder()
+	validatePositiveDuration(vb, raw, "zero", "zero")
+	validatePositiveDuration(vb, raw, "zero_string", "zero_string")
+	validatePositiveDuration(vb, raw, "valid", "valid")
+	resp := vb.Build()
+	if len(resp.Errors) != 2 || resp.Errors[0].Field != "zero" || resp.Errors[1].Field != "zero_string" {
+		t.Errorf("expected errors for the zero durations, got %v", resp.Errors)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultFanOutConcurrency is the initial number of concurrent requests in a fan-out.
+	defaultFanOutConcurrency = 4
+	// defaultFanOutMinConcurrency is the floor concurrency backs off to under rate limiting.
+	defaultFanOutMinConcurrency = 1
+	// defaultFanOutMaxConcurrency is the ceiling concurrency ramps up to.
+	defaultFanOutMaxConcurrency = 16
+	// defaultFanOutStagger is the maximum random delay between request starts.
+	defaultFanOutStagger = 50 * time.Millisecond
+)
+
+// ConcurrencyConfig bounds the adaptive concurrency of a fan-out.
+type ConcurrencyConfig struct {
+	Initial int `json:"initial"`
+	Min     int `json:"min"`
+	Max     int `json:"max"`
+}
+
+// normalized returns the limits with defaults applied and Initial clamped
+// to [Min, Max].
+func (c ConcurrencyConfig) normalized() ConcurrencyConfig {
+	if c.Min < 1 {
+		c.Min = defaultFanOutMinConcurrency
+	}
+	if c.Max < 1 {
+		c.Max = max(defaultFanOutMaxConcurrency, c.Min)
+	}
+	if c.Max < c.Min {
+		c.Max = c.Min
+	}
+	if c.Initial < 1 {
+		c.Initial = defaultFanOutConcurrency
+	}
+	c.Initial = min(max(c.Initial, c.Min), c.Max)
+	return c
+}
+
+// adaptiveLimiter limits the number of calls in flight using additive
+// increase/multiplicative decrease: every successful call raises the limit
+// by roughly one per window of calls, and every rate-limited call halves it.
+type adaptiveLimiter struct {
+	mu       sync.Mutex
+	limit    float64
+	min      float64
+	max      float64
+	inFlight int
+	wake     chan struct{}
+}
+
+func newAdaptiveLimiter(cfg ConcurrencyConfig) *adaptiveLimiter {
+	cfg = cfg.normalized()
+	return &adaptiveLimiter{
+		limit: float64(cfg.Initial),
+		min:   float64(cfg.Min),
+		max:   float64(cfg.Max),
+		wake:  make(chan struct{}),
+	}
+}
+
+// acquire blocks until a call may start or ctx is done.
+func (l *adaptiveLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// release ends a call and adjusts the limit from its outcome.
+func (l *adaptiveLimiter) release(rateLimited bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	if rateLimited {
+		l.limit = max(l.min, l.limit/2)
+	} else {
+		l.limit = min(l.max, l.limit+1/l.limit)
+	}
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// current returns the current concurrency limit.
+func (l *adaptiveLimiter) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// fanOut calls fn for each index in [0, n), adapting the number of calls in
+// flight within the concurrency limits: it backs off when Sentry responds
+// with 429 and ramps back up as calls succeed. Each call start is delayed by
+// a random stagger in [0, stagger) so a large fan-out does not hit Sentry's
+// rate limiter as a single burst.
+//
+// It returns the error from each call by index and the effective spread
+// between the first and last call start.
+func fanOut(ctx context.Context, n int, concurrency ConcurrencyConfig, stagger time.Duration, fn func(ctx context.Context, i int) error) ([]error, time.Duration) {
+	errs := make([]error, n)
+	if n == 0 {
+		return errs, 0
+	}
+
+	var wg sync.WaitGroup
+	limiter := newAdaptiveLimiter(concurrency)
+	var first, last time.Time
+
+	for i := 0; i < n; i++ {
+		if i > 0 && stagger > 0 {
+			timer := time.NewTimer(rand.N(stagger))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+
+		err := ctx.Err()
+		if err == nil {
+			err = limiter.acquire(ctx)
+		}
+		if err != nil {
+			for j := i; j < n; j++ {
+				errs[j] = err
+			}
+			break
+		}
+
+		last = time.Now()
+		if i == 0 {
+			first = last
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(ctx, i)
+			limiter.release(isRateLimited(errs[i]))
+		}(i)
+	}
+
+	wg.Wait()
+	return errs, last.Sub(first)
+}
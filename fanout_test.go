@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFanOut(t *testing.T) {
+	var calls, inFlight, maxInFlight atomic.Int32
+
+	errs, spread := fanOut(context.Background(), 10, ConcurrencyConfig{Initial: 3, Min: 1, Max: 3}, time.Millisecond, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		n := inFlight.Add(1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		inFlight.Add(-1)
+		if i == 4 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if calls.Load() != 10 {
+		t.Errorf("expected 10 calls, got %d", calls.Load())
+	}
+	if maxInFlight.Load() > 3 {
+		t.Errorf("expected at most 3 calls in flight, got %d", maxInFlight.Load())
+	}
+	for i, err := range errs {
+		if (err != nil) != (i == 4) {
+			t.Errorf("unexpected error at index %d: %v", i, err)
+		}
+	}
+	if spread < 0 {
+		t.Errorf("expected non-negative spread, got %s", spread)
+	}
+}
+
+func TestFanOutCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls atomic.Int32
+	errs, _ := fanOut(ctx, 5, ConcurrencyConfig{Initial: 2}, 0, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		return nil
+	})
+
+	if calls.Load() != 0 {
+		t.Errorf("expected no calls after cancellation, got %d", calls.Load())
+	}
+	for i, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled at index %d, got %v", i, err)
+		}
+	}
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	l := newAdaptiveLimiter(ConcurrencyConfig{Initial: 8, Min: 2, Max: 10})
+
+	for range 2 {
+		if err := l.acquire(context.Background()); err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+		l.release(true)
+	}
+	if got := l.current(); got != 2 {
+		t.Errorf("expected limit to halve to 2 after rate limiting, got %d", got)
+	}
+
+	l.release(true)
+	if got := l.current(); got != 2 {
+		t.Errorf("expected limit to stay at min 2, got %d", got)
+	}
+
+	for range 50 {
+		l.release(false)
+	}
+	if got := l.current(); got != 10 {
+		t.Errorf("expected limit to ramp up to max 10, got %d", got)
+	}
+}
+
+func TestFanOutBacksOffOnRateLimit(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	rateLimited := &StatusError{StatusCode: 429, Message: "rate limited"}
+
+	errs, _ := fanOut(context.Background(), 12, ConcurrencyConfig{Initial: 4, Min: 1, Max: 4}, 0, func(ctx context.Context, i int) error {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		if i >= 4 {
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		return rateLimited
+	})
+
+	for i, err := range errs {
+		if !isRateLimited(err) {
+			t.Errorf("expected rate limit error at index %d, got %v", i, err)
+		}
+	}
+	if maxInFlight.Load() > 2 {
+		t.Errorf("expected concurrency to back off after rate limiting, got %d in flight", maxInFlight.Load())
+	}
+}
+
+func TestConcurrencyConfigNormalized(t *testing.T) {
+	got := ConcurrencyConfig{Initial: 20, Min: 2, Max: 8}.normalized()
+	if got != (ConcurrencyConfig{Initial: 8, Min: 2, Max: 8}) {
+		t.Errorf("normalized() = %+v", got)
+	}
+	got = ConcurrencyConfig{}.normalized()
+	if got != (ConcurrencyConfig{Initial: defaultFanOutConcurrency, Min: defaultFanOutMinConcurrency, Max: defaultFanOutMaxConcurrency}) {
+		t.Errorf("normalized() defaults = %+v", got)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runGit runs a git command and returns its trimmed standard output.
+// It is a variable so tests can substitute a fake repository.
+var runGit = func(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// originURL returns the URL of the origin remote.
+func originURL(ctx context.Context) (string, error) {
+	out, err := runGit(ctx, "remote", "get-url", "origin")
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", fmt.Errorf("origin remote has no URL")
+	}
+	return out, nil
+}
+
+// changedFiles lists the files changed between two revisions.
+func changedFiles(ctx context.Context, from, to string) ([]string, error) {
+	out, err := runGit(ctx, "diff", "--name-only", from+".."+to)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// readTagAnnotation returns the message of an annotated tag. Lightweight
+// tags have no annotation and yield an empty message.
+func readTagAnnotation(ctx context.Context, tag string) (string, error) {
+	out, err := runGit(ctx, "for-each-ref", "--format=%(objecttype)%00%(contents)", "refs/tags/"+tag)
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", fmt.Errorf("tag %s not found", tag)
+	}
+	objectType, contents, _ := strings.Cut(out, "\x00")
+	if objectType != "tag" {
+		return "", nil
+	}
+	return strings.TrimSpace(contents), nil
+}
+
+// commitInfo holds author metadata read from git.
+type commitInfo struct {
+	AuthorName  string
+	AuthorEmail string
+	Timestamp   string
+}
+
+// readCommitInfo reads a commit's author name, email, and date from git.
+func readCommitInfo(ctx context.Context, hash string) (commitInfo, error) {
+	out, err := runGit(ctx, "show", "-s", "--format=%an%x00%ae%x00%aI", hash)
+	if err != nil {
+		return commitInfo{}, err
+	}
+	parts := strings.SplitN(out, "\x00", 3)
+	if len(parts) != 3 {
+		return commitInfo{}, fmt.Errorf("unexpected git show output for %s", hash)
+	}
+	return commitInfo{AuthorName: parts[0], AuthorEmail: parts[1], Timestamp: parts[2]}, nil
+}
+
+// enrichCommitsFromGit fills in missing author names, emails and timestamps
+// from the local git repository. Fields that are already set and commits that
+// git cannot resolve are left unchanged.
+func enrichCommitsFromGit(ctx context.Context, commits []CommitSpec) error {
+	if _, err := runGit(ctx, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return fmt.Errorf("no git repository available to enrich commits: %w", err)
+	}
+
+	for i := range commits {
+		c := &commits[i]
+		if c.AuthorName != "" && c.AuthorEmail != "" && c.Timestamp != "" {
+			continue
+		}
+		info, err := readCommitInfo(ctx, c.ID)
+		if err != nil {
+			continue
+		}
+		if c.AuthorName == "" {
+			c.AuthorName = info.AuthorName
+		}
+		if c.AuthorEmail == "" {
+			c.AuthorEmail = info.AuthorEmail
+		}
+		if c.Timestamp == "" {
+			c.Timestamp = info.Timestamp
+		}
+	}
+	return nil
+}
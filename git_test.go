@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEnrichCommitsFromGit(t *testing.T) {
+	origRunGit := runGit
+	defer func() { runGit = origRunGit }()
+
+	runGit = func(ctx context.Context, args ...string) (string, error) {
+		switch args[0] {
+		case "rev-parse":
+			return "true", nil
+		case "show":
+			switch args[len(args)-1] {
+			case "abc123", "def456":
+				return "Jane Doe\x00jane@example.com\x002024-01-02T03:04:05Z", nil
+			}
+		}
+		return "", errors.New("unknown revision")
+	}
+
+	commits := []CommitSpec{
+		{ID: "abc123", Repository: "org/repo"},
+		{ID: "missing", Repository: "org/repo", Timestamp: "2024-06-01T00:00:00Z"},
+		{ID: "def456", Repository: "org/repo", Timestamp: "2024-03-04T05:06:07Z"},
+	}
+	if err := enrichCommitsFromGit(context.Background(), commits); err != nil {
+		t.Fatalf("enrichCommitsFromGit() error = %v", err)
+	}
+
+	if commits[0].AuthorName != "Jane Doe" || commits[0].AuthorEmail != "jane@example.com" {
+		t.Errorf("expected author to be enriched, got %+v", commits[0])
+	}
+	if commits[0].Timestamp != "2024-01-02T03:04:05Z" {
+		t.Errorf("expected timestamp from git, got %q", commits[0].Timestamp)
+	}
+	if commits[1].AuthorName != "" || commits[1].Timestamp != "2024-06-01T00:00:00Z" {
+		t.Errorf("expected unresolvable commit to be unchanged, got %+v", commits[1])
+	}
+	if commits[2].AuthorName != "Jane Doe" || commits[2].Timestamp != "2024-03-04T05:06:07Z" {
+		t.Errorf("expected preset timestamp to survive enrichment, got %+v", commits[2])
+	}
+}
+
+func TestEnrichCommitsFromGitNoRepository(t *testing.T) {
+	origRunGit := runGit
+	defer func() { runGit = origRunGit }()
+
+	runGit = func(ctx context.Context, args ...string) (string, error) {
+		return "", errors.New("not a git repository")
+	}
+
+	err := enrichCommitsFromGit(context.Background(), []CommitSpec{{ID: "abc123"}})
+	if err == nil || !strings.Contains(err.Error(), "no git repository available") {
+		t.Errorf("expected clear repository error, got %v", err)
+	}
+}
+
+func TestReadTagAnnotation(t *testing.T) {
+	origRunGit := runGit
+	defer func() { runGit = origRunGit }()
+
+	runGit = func(ctx context.Context, args ...string) (string, error) {
+		switch args[len(args)-1] {
+		case "refs/tags/v1.0.0":
+			return "tag\x00Release 1.0.0\n\nFirst stable release", nil
+		case "refs/tags/v0.9.0":
+			return "commit\x00Fix bug", nil
+		}
+		return "", nil
+	}
+
+	tests := []struct {
+		tag      string
+		expected string
+		wantErr  bool
+	}{
+		{"v1.0.0", "Release 1.0.0\n\nFirst stable release", false},
+		{"v0.9.0", "", false},
+		{"v0.1.0", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := readTagAnnotation(context.Background(), tt.tag)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("readTagAnnotation(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+		}
+		if got != tt.expected {
+			t.Errorf("readTagAnnotation(%q) = %q, want %q", tt.tag, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// defaultIssuePattern matches Jira/Linear style issue keys such as PROJ-123.
+const defaultIssuePattern = `\b[A-Z][A-Z0-9]+-[0-9]+\b`
+
+// extractIssueKeys returns the unique issue keys referenced by the commits,
+// in order of first appearance.
+func extractIssueKeys(commits []plugin.ConventionalCommit, pattern *regexp.Regexp) []string {
+	seen := make(map[string]bool)
+	var keys []string
+
+	add := func(key string) {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for _, c := range commits {
+		for _, key := range pattern.FindAllString(c.Description, -1) {
+			add(key)
+		}
+		for _, key := range pattern.FindAllString(c.Body, -1) {
+			add(key)
+		}
+		for _, issue := range c.Issues {
+			if pattern.MatchString(issue) {
+				add(pattern.FindString(issue))
+			}
+		}
+	}
+
+	return keys
+}
+
+// issueVersionInfo returns the issue keys as release version info entries.
+func issueVersionInfo(keys []string) map[string]string {
+	return map[string]string{"issues": strings.Join(keys, ",")}
+}
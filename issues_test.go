@@ -0,0 +1,40 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestExtractIssueKeys(t *testing.T) {
+	commits := []plugin.ConventionalCommit{
+		{Hash: "a", Description: "add login flow PROJ-123"},
+		{Hash: "b", Description: "fix crash", Body: "Fixes PROJ-124 and ENG-7"},
+		{Hash: "c", Description: "follow-up for PROJ-123"},
+		{Hash: "d", Description: "no issue here", Issues: []string{"OPS-9"}},
+	}
+
+	keys := extractIssueKeys(commits, regexp.MustCompile(defaultIssuePattern))
+
+	expected := []string{"PROJ-123", "PROJ-124", "ENG-7", "OPS-9"}
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+	for i, key := range expected {
+		if keys[i] != key {
+			t.Errorf("expected key %d to be %s, got %s", i, key, keys[i])
+		}
+	}
+}
+
+func TestExtractIssueKeysCustomPattern(t *testing.T) {
+	commits := []plugin.ConventionalCommit{
+		{Hash: "a", Description: "fix #42 and PROJ-1"},
+	}
+
+	keys := extractIssueKeys(commits, regexp.MustCompile(`#[0-9]+`))
+	if len(keys) != 1 || keys[0] != "#42" {
+		t.Errorf("expected [#42], got %v", keys)
+	}
+}
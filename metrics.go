@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMetricsJob is the Pushgateway job label for pushed metrics.
+	defaultMetricsJob = "relicta_sentry"
+	// metricsPushTimeout bounds the request to the Pushgateway.
+	metricsPushTimeout = 10 * time.Second
+)
+
+// Counter names recorded for a plugin run.
+const (
+	metricReleasesCreated   = "releases_created"
+	metricDeploysCreated    = "deploys_created"
+	metricCommitsAssociated = "commits_associated"
+	metricAPIErrors         = "api_errors"
+)
+
+// MetricsConfig contains Prometheus Pushgateway settings.
+type MetricsConfig struct {
+	PushgatewayURL string `json:"pushgateway_url"`
+	Job            string `json:"job"`
+}
+
+// runMetrics counts Sentry integration events during a single run. A nil
+// *runMetrics discards all counts.
+type runMetrics struct {
+	mu       sync.Mutex
+	counters map[string]int64
+}
+
+func newRunMetrics() *runMetrics {
+	return &runMetrics{counters: map[string]int64{
+		metricReleasesCreated:   0,
+		metricDeploysCreated:    0,
+		metricCommitsAssociated: 0,
+		metricAPIErrors:         0,
+	}}
+}
+
+// add increments a counter by n.
+func (m *runMetrics) add(name string, n int64) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name] += n
+}
+
+// exposition renders the counters in the Prometheus text format.
+func (m *runMetrics) exposition() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.counters))
+	for name := range m.counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "# TYPE sentry_plugin_%s counter\n", name)
+		fmt.Fprintf(&b, "sentry_plugin_%s %d\n", name, m.counters[name])
+	}
+	return b.String()
+}
+
+// pushMetrics replaces the metrics for the configured job on the Pushgateway.
+func pushMetrics(ctx context.Context, cfg MetricsConfig, m *runMetrics) error {
+	job := cfg.Job
+	if job == "" {
+		job = defaultMetricsJob
+	}
+	endpoint := strings.TrimSuffix(cfg.PushgatewayURL, "/") + "/metrics/job/" + url.PathEscape(job)
+
+	ctx, cancel := context.WithTimeout(ctx, metricsPushTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewBufferString(m.exposition()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pushgateway error: %s (status %d)", strings.TrimSpace(string(body)), resp.StatusCode)
+	}
+	return nil
+}
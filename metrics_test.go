@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestRunMetricsExposition(t *testing.T) {
+	m := newRunMetrics()
+	m.add(metricReleasesCreated, 1)
+	m.add(metricCommitsAssociated, 3)
+
+	var nilMetrics *runMetrics
+	nilMetrics.add(metricAPIErrors, 1)
+
+	got := m.exposition()
+	for _, line := range []string{
+		"# TYPE sentry_plugin_releases_created counter",
+		"sentry_plugin_releases_created 1",
+		"sentry_plugin_commits_associated 3",
+		"sentry_plugin_api_errors 0",
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("expected exposition to contain %q, got:\n%s", line, got)
+		}
+	}
+}
+
+func TestExecutePushesMetrics(t *testing.T) {
+	var pushedPath, pushed string
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		pushedPath, pushed = r.URL.Path, string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	sentry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/deploys/") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer sentry.Close()
+
+	p := &SentryPlugin{}
+	_, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"auth_token": "test-token",
+			"org":        "my-org",
+			"project":    "my-project",
+			"url":        sentry.URL,
+			"metrics": map[string]any{
+				"pushgateway_url": gateway.URL,
+				"job":             "web",
+			},
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if pushedPath != "/metrics/job/web" {
+		t.Errorf("expected push to /metrics/job/web, got %q", pushedPath)
+	}
+	if !strings.Contains(pushed, "sentry_plugin_api_errors 1\n") || !strings.Contains(pushed, "sentry_plugin_deploys_created 0\n") {
+		t.Errorf("unexpected pushed metrics:\n%s", pushed)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// defaultPackageJSONPath is the package.json read for the release prefix.
+const defaultPackageJSONPath = "package.json"
+
+// readPackageName returns the "name" field of a package.json file.
+func readPackageName(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read package.json for the release prefix: %w", err)
+	}
+
+	var pkg struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return "", fmt.Errorf("invalid package.json %s: %w", path, err)
+	}
+	if pkg.Name == "" {
+		return "", fmt.Errorf("package.json %s has no name", path)
+	}
+	return pkg.Name, nil
+}
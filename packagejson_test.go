@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestReadPackageName(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		wantErr  string
+	}{
+		{"scoped package", write("scoped.json", `{"name": "@acme/web", "version": "1.0.0"}`), "@acme/web", ""},
+		{"missing file", filepath.Join(dir, "missing.json"), "", "failed to read package.json"},
+		{"invalid json", write("invalid.json", `{`), "", "invalid package.json"},
+		{"no name", write("noname.json", `{"version": "1.0.0"}`), "", "has no name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPackageName(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readPackageName() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("readPackageName() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteReleasePrefixFromPackageJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(path, []byte(`{"name": "web"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &SentryPlugin{}
+	for _, tt := range []struct {
+		path        string
+		wantSuccess bool
+	}{
+		{path, true},
+		{path + ".missing", false},
+	} {
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookPrePublish,
+			Config: map[string]any{
+				"auth_token":                       "test-token",
+				"org":                              "my-org",
+				"project":                          "my-project",
+				"ci_metadata":                      false,
+				"release_prefix_from_package_json": true,
+				"package_json_path":                tt.path,
+			},
+			Context: plugin.ReleaseContext{Version: "1.0.0"},
+			DryRun:  true,
+		})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if resp.Success != tt.wantSuccess {
+			t.Fatalf("Success = %v, want %v (%s)", resp.Success, tt.wantSuccess, resp.Error)
+		}
+		if tt.wantSuccess && resp.Outputs["version"] != "web@1.0.0" {
+			t.Errorf("expected version web@1.0.0, got %v", resp.Outputs["version"])
+		}
+		if !tt.wantSuccess && !strings.Contains(resp.Error, "package.json") {
+			t.Errorf("expected clear package.json error, got: %s", resp.Error)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxPages bounds how many pages a list request follows, in case a server
+// keeps reporting more results.
+const maxPages = 100
+
+// ListOptions filters and pages list requests.
+type ListOptions struct {
+	// Query is a search filter, e.g. a version prefix for releases.
+	Query string
+	// PerPage sets how many results Sentry returns per page; zero keeps
+	// Sentry's default.
+	PerPage int
+}
+
+// encode returns the options as a query string, including the leading
+// "?", or "" when no option is set.
+func (o ListOptions) encode() string {
+	query := url.Values{}
+	if o.Query != "" {
+		query.Set("query", o.Query)
+	}
+	if o.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+	if len(query) == 0 {
+		return ""
+	}
+	return "?" + query.Encode()
+}
+
+// listAll fetches every page of a list endpoint, following the cursors in
+// Sentry's Link response header.
+func listAll[T any](ctx context.Context, c *SentryClient, category, endpoint string) ([]T, error) {
+	var all []T
+	cursor := ""
+	for range maxPages {
+		pageEndpoint := endpoint
+		if cursor != "" {
+			sep := "?"
+			if strings.Contains(endpoint, "?") {
+				sep = "&"
+			}
+			pageEndpoint += sep + "cursor=" + url.QueryEscape(cursor)
+		}
+
+		var page []T
+		header, err := c.requestWithHeader(ctx, category, http.MethodGet, pageEndpoint, nil, "application/json", &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+
+		if cursor = nextCursor(header.Get("Link")); cursor == "" {
+			break
+		}
+	}
+	return all, nil
+}
+
+// nextCursor returns the cursor of the next page from a Sentry Link header,
+// e.g. `<...>; rel="next"; results="true"; cursor="0:100:0"`, or "" on the
+// last page.
+func nextCursor(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		var next, results bool
+		var cursor string
+		for _, attr := range strings.Split(part, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(attr), "=")
+			if !ok {
+				continue
+			}
+			value = strings.Trim(value, `"`)
+			switch key {
+			case "rel":
+				next = value == "next"
+			case "results":
+				results = value == "true"
+			case "cursor":
+				cursor = value
+			}
+		}
+		if next && results {
+			return cursor
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNextCursor(t *testing.T) {
+	tests := []struct {
+		name     string
+		link     string
+		expected string
+	}{
+		{
+			name:     "more results",
+			link:     `<https://sentry.io/api/0/organizations/my-org/repos/?&cursor=0:0:1>; rel="previous"; results="false"; cursor="0:0:1", <https://sentry.io/api/0/organizations/my-org/repos/?&cursor=0:100:0>; rel="next"; results="true"; cursor="0:100:0"`,
+			expected: "0:100:0",
+		},
+		{
+			name: "last page",
+			link: `<https://sentry.io/api/0/organizations/my-org/repos/?&cursor=0:0:1>; rel="previous"; results="true"; cursor="0:0:1", <https://sentry.io/api/0/organizations/my-org/repos/?&cursor=0:200:0>; rel="next"; results="false"; cursor="0:200:0"`,
+		},
+		{name: "no header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextCursor(tt.link); got != tt.expected {
+				t.Errorf("nextCursor() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSentryClientListRepositoriesPaginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/0/organizations/my-org/repos/" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch cursor := r.URL.Query().Get("cursor"); cursor {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"; results="true"; cursor="0:1:0"`, r.URL))
+			_, _ = w.Write([]byte(`[{"id": "1", "name": "org/api", "provider": {"id": "integrations:github", "name": "GitHub"}, "externalSlug": "org/api"}]`))
+		case "0:1:0":
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"; results="false"; cursor="0:2:0"`, r.URL))
+			_, _ = w.Write([]byte(`[{"id": "2", "name": "org/web", "provider": {"id": "integrations:gitlab", "name": "GitLab"}, "externalSlug": 4242}]`))
+		default:
+			t.Errorf("unexpected cursor %q", cursor)
+		}
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	repos, err := client.ListRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if len(repos) != 2 || repos[0].Name != "org/api" || repos[1].Name != "org/web" {
+		t.Fatalf("unexpected repositories: %+v", repos)
+	}
+	if repos[0].Provider.ID != "integrations:github" || repos[0].ExternalSlug != "org/api" {
+		t.Errorf("unexpected provider fields: %+v", repos[0])
+	}
+	if repos[1].ExternalSlug != "4242" {
+		t.Errorf("expected numeric external slug to decode, got %q", repos[1].ExternalSlug)
+	}
+}
+
+func TestSentryClientListReleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/0/organizations/my-org/releases/" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("query") != "web@" || query.Get("per_page") != "50" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch cursor := query.Get("cursor"); cursor {
+		case "":
+			w.Header().Set("Link", `<x>; rel="previous"; results="false"; cursor="0:0:1", <x>; rel="next"; results="true"; cursor="0:50:0"`)
+			_, _ = w.Write([]byte(`[{"version": "web@2.0.0"}]`))
+		case "0:50:0":
+			w.Header().Set("Link", `<x>; rel="next"; results="false"; cursor="0:100:0"`)
+			_, _ = w.Write([]byte(`[{"version": "web@1.0.0"}]`))
+		default:
+			t.Errorf("unexpected cursor %q", cursor)
+		}
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	releases, err := client.ListReleases(context.Background(), ListOptions{Query: "web@", PerPage: 50})
+	if err != nil {
+		t.Fatalf("ListReleases() error = %v", err)
+	}
+	if len(releases) != 2 || releases[0].Version != "web@2.0.0" || releases[1].Version != "web@1.0.0" {
+		t.Errorf("unexpected releases: %+v", releases)
+	}
+}
+
+func TestSentryClientListProjectsPaginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") == "" {
+			w.Header().Set("Link", `<next>; rel="next"; results="true"; cursor="0:1:0"`)
+			_, _ = w.Write([]byte(`[{"slug": "web"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"slug": "api"}]`))
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	for name, list := range map[string]func() ([]Project, error){
+		"org":  func() ([]Project, error) { return client.ListProjects(context.Background()) },
+		"team": func() ([]Project, error) { return client.ListTeamProjects(context.Background(), "web-team") },
+	} {
+		projects, err := list()
+		if err != nil {
+			t.Fatalf("%s: list error = %v", name, err)
+		}
+		if len(projects) != 2 || projects[0].Slug != "web" || projects[1].Slug != "api" {
+			t.Errorf("%s: expected projects from both pages, got %+v", name, projects)
+		}
+	}
+}
@@ -4,8 +4,18 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -16,37 +26,334 @@ import (
 // Version is set at build time.
 var Version = "0.1.0"
 
+// Step status values reported in the "status" output.
+const (
+	statusOK      = "ok"
+	statusPartial = "partial"
+	statusFailed  = "failed"
+)
+
+// Policies for PrePublish runs against a release that was already finalized.
+const (
+	releasedPolicySkip     = "skip"
+	releasedPolicyFail     = "fail"
+	releasedPolicyRecreate = "recreate"
+)
+
+// maxVersionLength is the longest release version Sentry accepts.
+const maxVersionLength = 200
+
+// sampleReleaseContext is used to render templates at validation time,
+// before the real release context is known.
+var sampleReleaseContext = plugin.ReleaseContext{
+	Version:   "1.0.0-rc.1",
+	TagName:   "v1.0.0-rc.1",
+	CommitSHA: "0123456789abcdef0123456789abcdef01234567",
+	Branch:    "main",
+}
+
+// validationWarningCode marks validation findings that do not make the
+// configuration invalid.
+const validationWarningCode = "warning"
+
+// Actions the OnError hook can take to roll back a failed release.
+const (
+	onErrorDeleteRelease  = "delete_release"
+	onErrorArchiveRelease = "archive_release"
+)
+
+// Policies for commits with an empty description.
+const (
+	emptyMessageSend        = "send"
+	emptyMessageSkip        = "skip"
+	emptyMessagePlaceholder = "placeholder"
+)
+
+// emptyMessagePlaceholderText replaces empty commit descriptions under the
+// placeholder policy.
+const emptyMessagePlaceholderText = "(no commit message)"
+
+// Orders in which commits are sent to Sentry, which lists them as sent.
+const (
+	commitsSortCategory      = "category"
+	commitsSortType          = "type"
+	commitsSortChronological = "chronological"
+	commitsSortAsIs          = "as-is"
+)
+
+// Scopes for the commit range associated with a release.
+const (
+	commitsScopeRelease = "release"
+	commitsScopeDeploy  = "deploy"
+)
+
+// commitSHAPattern matches an abbreviated or full SHA-1 or SHA-256 commit
+// hash.
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
+// hostnamePattern matches a DNS hostname made of dot-separated labels.
+var hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// timeNow returns the current time for version templates and commit dates.
+// It is a variable so tests can fix the clock.
+var timeNow = time.Now
+
 // SentryPlugin implements the plugin.Plugin interface for Sentry integration.
 type SentryPlugin struct{}
 
 // Config represents Sentry plugin configuration.
 type Config struct {
-	AuthToken        string           `json:"auth_token"`
-	Org              string           `json:"org"`
-	Project          string           `json:"project"`
-	Projects         []string         `json:"projects"`
-	URL              string           `json:"url"`
-	VersionFormat    string           `json:"version_format"`
-	Environment      string           `json:"environment"`
-	SetCommits       bool             `json:"set_commits"`
-	Commits          CommitsConfig    `json:"commits"`
-	CreateDeploy     bool             `json:"create_deploy"`
-	Deploy           DeployConfig     `json:"deploy"`
-	UploadSourcemaps bool             `json:"upload_sourcemaps"`
-	Sourcemaps       SourcemapsConfig `json:"sourcemaps"`
-	Finalize         bool             `json:"finalize"`
+	AuthToken                 string                   `json:"auth_token"`
+	Org                       string                   `json:"org"`
+	Project                   string                   `json:"project"`
+	Projects                  []string                 `json:"projects"`
+	URL                       string                   `json:"url"`
+	VersionFormat             string                   `json:"version_format"`
+	Environment               string                   `json:"environment"`
+	SetCommits                bool                     `json:"set_commits"`
+	Commits                   CommitsConfig            `json:"commits"`
+	CreateDeploy              bool                     `json:"create_deploy"`
+	Deploy                    DeployConfig             `json:"deploy"`
+	UploadSourcemaps          bool                     `json:"upload_sourcemaps"`
+	Sourcemaps                SourcemapsConfig         `json:"sourcemaps"`
+	Finalize                  bool                     `json:"finalize"`
+	CIMetadata                bool                     `json:"ci_metadata"`
+	Summary                   bool                     `json:"summary"`
+	TLSServerName             string                   `json:"tls_server_name"`
+	OnlyIfChanged             []string                 `json:"only_if_changed"`
+	AssociateIssues           bool                     `json:"associate_issues"`
+	IssuePattern              string                   `json:"issue_pattern"`
+	CommitHashLength          int                      `json:"commit_hash_length"`
+	ShortSHALength            int                      `json:"short_sha_length"`
+	FanOutStagger             time.Duration            `json:"fan_out_stagger"`
+	Concurrency               ConcurrencyConfig        `json:"concurrency"`
+	ReleasedReleasePolicy     string                   `json:"released_release_policy"`
+	Outputs                   []string                 `json:"outputs"`
+	MinSuccessfulProjects     string                   `json:"min_successful_projects"`
+	PreviousVersionFile       string                   `json:"previous_version_file"`
+	BestEffort                bool                     `json:"best_effort"`
+	EnrichCommitsFromGit      bool                     `json:"enrich_commits_from_git"`
+	SkipFinalizeForPrerelease bool                     `json:"skip_finalize_for_prerelease"`
+	Release                   ReleaseConfig            `json:"release"`
+	SkipIfNoToken             bool                     `json:"skip_if_no_token"`
+	Metrics                   MetricsConfig            `json:"metrics"`
+	Channels                  map[string]string        `json:"channels"`
+	Audit                     bool                     `json:"audit"`
+	OnError                   OnErrorConfig            `json:"on_error"`
+	ArtifactChecksums         map[string]string        `json:"artifact_checksums"`
+	ArtifactChecksumsFile     string                   `json:"artifact_checksums_file"`
+	Timeouts                  map[string]time.Duration `json:"timeouts"`
+	MinCommits                int                      `json:"min_commits"`
+	Retry                     RetryConfig              `json:"retry"`
+	RequireSemver             bool                     `json:"require_semver"`
+	AdoptExisting             bool                     `json:"adopt_existing"`
+	ExportConfig              bool                     `json:"export_config"`
+	FinalizeWebhook           WebhookConfig            `json:"finalize_webhook"`
+	ReleaseWebhook            WebhookConfig            `json:"release_webhook"`
+	ValidateOnExecute         bool                     `json:"validate_on_execute"`
+	TagAnnotation             bool                     `json:"tag_annotation"`
+	ParallelSteps             bool                     `json:"parallel_steps"`
+	ProtectFinalized          bool                     `json:"protect_finalized"`
+	Force                     bool                     `json:"force"`
+	ChannelMetadata           bool                     `json:"channel_metadata"`
+	GitHubReleaseURL          bool                     `json:"github_release_url"`
+	ValidateEnvironment       bool                     `json:"validate_environment"`
+	AllowNewEnvironments      []string                 `json:"allow_new_environments,omitempty"`
+	ValidateRepository        bool                     `json:"validate_repository"`
+	ListRepositories          bool                     `json:"list_repositories"`
+	RateLimit                 float64                  `json:"rate_limit,omitempty"`
+	FallbackURL               string                   `json:"fallback_url,omitempty"`
+	ExpectedOrgID             string                   `json:"expected_org_id,omitempty"`
+	ProjectVersionFormats     map[string]string        `json:"project_version_formats,omitempty"`
+	ProxyURL                  string                   `json:"proxy_url,omitempty"`
+	CACertPath                string                   `json:"ca_cert_path,omitempty"`
+	InsecureSkipVerify        bool                     `json:"insecure_skip_verify,omitempty"`
+	Debug                     bool                     `json:"debug,omitempty"`
+
+	// RequireExplicitEnvironment drops the "production" default, so a
+	// build that never configures an environment fails instead of
+	// recording production deploys.
+	RequireExplicitEnvironment bool `json:"require_explicit_environment"`
+
+	// ReleasePrefix is prepended to version_format and to every per-project
+	// version_format, e.g. "my-app@". With
+	// ReleasePrefixFromPackageJSON it is the package name read from
+	// PackageJSONPath followed by "@".
+	ReleasePrefix                string `json:"release_prefix"`
+	ReleasePrefixFromPackageJSON bool   `json:"release_prefix_from_package_json"`
+	PackageJSONPath              string `json:"package_json_path"`
+
+	// PreviousVersion is read from PreviousVersionFile and overrides the
+	// previous version in the release context.
+	PreviousVersion    string `json:"-"`
+	previousVersionErr error
+
+	// artifactChecksumsErr records a failure to read ArtifactChecksumsFile.
+	artifactChecksumsErr error
+
+	// releasePrefixErr records a failure to read the package name for
+	// ReleasePrefixFromPackageJSON.
+	releasePrefixErr error
+	// rootCAs holds the certificates read from CACertPath, and caCertErr
+	// records a failure to read them.
+	rootCAs   *x509.CertPool
+	caCertErr error
+	// proxyErr records an invalid ProxyURL.
+	proxyErr error
+
+	// deprecatedKeys lists the deprecated config keys that were set.
+	deprecatedKeys []string
+	// onlyIfChangedErr records a failure to evaluate OnlyIfChanged, in which
+	// case the hook runs anyway.
+	onlyIfChangedErr error
+
+	// metrics counts events for the Pushgateway; nil when metrics are off.
+	metrics *runMetrics
+	// audit records API calls for the audit_log output; nil when off.
+	audit *auditLog
+	// apiTime accumulates time spent in API calls for api_duration_ms.
+	apiTime *apiTimer
 }
 
 // CommitsConfig contains commit association settings.
 type CommitsConfig struct {
-	Auto       bool   `json:"auto"`
-	Repository string `json:"repository"`
+	// Auto lets Sentry determine the commits from its repository
+	// integrations instead of sending the release's commits.
+	Auto                bool                `json:"auto"`
+	Repository          string              `json:"repository"`
+	RepositoryTransform RepositoryTransform `json:"repository_transform"`
+	Scope               string              `json:"scope"`
+	EmptyMessagePolicy  string              `json:"empty_message_policy"`
+	Sort                string              `json:"sort"`
+	IncludeTypeScope    bool                `json:"include_type_scope"`
+
+	// ScopeRepositories maps conventional commit scopes to the repository
+	// their commits are associated with. Unmapped scopes use Repository.
+	ScopeRepositories map[string]string `json:"scope_repositories,omitempty"`
+
+	// From and To override the commit range Sentry resolves through its
+	// repository integration. To defaults to the release commit.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// ReleaseConfig contains advanced release creation settings.
+type ReleaseConfig struct {
+	// Ref is the head commit recorded on the release. It defaults to the
+	// release context's commit SHA.
+	Ref string `json:"ref,omitempty"`
+	// QueryParams are appended to the release creation request URL. They
+	// are an escape hatch for API behavior the plugin does not model.
+	QueryParams map[string]string `json:"query_params,omitempty"`
+}
+
+// OnErrorConfig contains the rollback actions taken by the OnError hook.
+type OnErrorConfig struct {
+	Actions []string `json:"actions,omitempty"`
+	// FailOnError reports the hook as failed when an action fails.
+	FailOnError bool `json:"fail_on_error"`
+	// RollbackDelay is waited before deleting the release, leaving time to
+	// inspect the failed release in Sentry.
+	RollbackDelay time.Duration `json:"rollback_delay,omitempty"`
 }
 
 // DeployConfig contains deploy tracking settings.
 type DeployConfig struct {
-	Environment string `json:"environment"`
-	Name        string `json:"name,omitempty"`
+	Environment  string   `json:"environment"`
+	Environments []string `json:"environments,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Atomic       bool     `json:"atomic,omitempty"`
+
+	// NameDefault is a template for the deploy name when Name is unset,
+	// rendered with the release version and the deploy environment.
+	NameDefault string `json:"name_default,omitempty"`
+
+	// CommitRange records the commits since the previous deploy to each
+	// environment in the deploy_commit_range output.
+	CommitRange bool `json:"commit_range,omitempty"`
+
+	// DeployedBy identifies who shipped the deploy, e.g. the CI actor.
+	DeployedBy string `json:"deployed_by,omitempty"`
+
+	// HealthcheckURL must respond with 200 OK before deploys are created.
+	HealthcheckURL     string        `json:"healthcheck_url,omitempty"`
+	HealthcheckTimeout time.Duration `json:"healthcheck_timeout,omitempty"`
+}
+
+// renderEnvironments renders templated environment names, such as
+// "preview-{{.Branch}}", with the release context.
+func (cfg *Config) renderEnvironments(releaseCtx plugin.ReleaseContext) error {
+	var err error
+	if cfg.Environment, err = renderContextTemplate("environment", cfg.Environment, cfg.ShortSHALength, releaseCtx); err != nil {
+		return err
+	}
+	if cfg.Deploy.Environment, err = renderContextTemplate("environment", cfg.Deploy.Environment, cfg.ShortSHALength, releaseCtx); err != nil {
+		return err
+	}
+	for i, env := range cfg.Deploy.Environments {
+		if cfg.Deploy.Environments[i], err = renderContextTemplate("environment", env, cfg.ShortSHALength, releaseCtx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// missingEnvironment reports whether deploys would be recorded without an
+// environment while require_explicit_environment is set.
+func (cfg *Config) missingEnvironment() bool {
+	if !cfg.RequireExplicitEnvironment || !cfg.CreateDeploy {
+		return false
+	}
+	return slices.Contains(cfg.Deploy.environments(), "")
+}
+
+// environments returns the environments to record deploys for.
+func (d DeployConfig) environments() []string {
+	if len(d.Environments) > 0 {
+		return d.Environments
+	}
+	return []string{d.Environment}
+}
+
+// nameFor returns the deploy name for env: Name when set, otherwise
+// NameDefault rendered with the version and environment. An empty name
+// lets Sentry generate one.
+func (d DeployConfig) nameFor(version, env string) (string, error) {
+	if d.Name != "" || d.NameDefault == "" {
+		return d.Name, nil
+	}
+
+	tmpl, err := newTemplate("deploy.name_default", d.NameDefault)
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		Version     string
+		Environment string
+	}{
+		Version:     version,
+		Environment: env,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// displayName returns the deploy name sent to Sentry. Sentry deploys have no
+// free-form metadata, so the deployer identity is recorded in the name.
+func (d DeployConfig) displayName() string {
+	switch {
+	case d.DeployedBy == "":
+		return d.Name
+	case d.Name == "":
+		return "deployed by " + d.DeployedBy
+	default:
+		return fmt.Sprintf("%s (deployed by %s)", d.Name, d.DeployedBy)
+	}
 }
 
 // SourcemapsConfig contains source map upload settings.
@@ -55,6 +362,16 @@ type SourcemapsConfig struct {
 	URLPrefix string   `json:"url_prefix"`
 	Include   []string `json:"include"`
 	Exclude   []string `json:"exclude"`
+
+	// Prune deletes files of the same release version older than
+	// PruneOlderThan. Files attached to earlier releases are left alone, so
+	// only re-uploads to an existing version free anything.
+	Prune          bool          `json:"prune"`
+	PruneOlderThan time.Duration `json:"prune_older_than"`
+
+	// ContentTypes overrides the content type uploaded per file extension,
+	// e.g. ".js.map".
+	ContentTypes map[string]string `json:"content_types,omitempty"`
 }
 
 // GetInfo returns plugin metadata.
@@ -76,193 +393,2127 @@ func (p *SentryPlugin) GetInfo() plugin.Info {
 func (p *SentryPlugin) Execute(ctx context.Context, req plugin.ExecuteRequest) (*plugin.ExecuteResponse, error) {
 	cfg := p.parseConfig(req.Config)
 
+	// Capture the resolved config before the hook renders templates into it
+	var effectiveConfig string
+	if cfg.ExportConfig {
+		effectiveConfig = cfg.effectiveJSON()
+	}
+
+	resp, err := p.execute(ctx, cfg, req)
+	if err != nil {
+		if !cfg.BestEffort {
+			return nil, err
+		}
+		resp = &plugin.ExecuteResponse{Success: false, Error: err.Error()}
+	}
+
+	if cfg.BestEffort && !resp.Success {
+		resp = downgradeFailure(resp)
+	}
+
+	// Attach the time spent in, and the record of, API calls made during the run
+	if resp.Outputs == nil {
+		resp.Outputs = make(map[string]any)
+	}
+	resp.Outputs["api_duration_ms"] = cfg.apiTime.milliseconds()
+	if cfg.audit != nil {
+		resp.Outputs["audit_log"] = cfg.audit.list()
+	}
+	if effectiveConfig != "" {
+		resp.Outputs["effective_config"] = effectiveConfig
+	}
+
+	// Point deprecated keys at their replacements
+	for _, key := range cfg.deprecatedKeys {
+		resp.Message = strings.TrimPrefix(resp.Message+"; Warning: "+deprecationMessage(key), "; ")
+	}
+
+	// Say why only_if_changed did not skip the hook
+	if cfg.onlyIfChangedErr != nil {
+		resp.Message = strings.TrimPrefix(resp.Message+"; Warning: Could not evaluate only_if_changed, running anyway: "+cfg.onlyIfChangedErr.Error(), "; ")
+	}
+
+	// Never let disabled certificate verification go unnoticed
+	if cfg.InsecureSkipVerify {
+		resp.Message = strings.TrimPrefix(resp.Message+"; Warning: "+insecureSkipVerifyWarning, "; ")
+	}
+
+	// Push run metrics to the Pushgateway
+	if cfg.metrics != nil && !req.DryRun {
+		if err := pushMetrics(ctx, cfg.Metrics, cfg.metrics); err != nil {
+			resp.Message = strings.TrimPrefix(resp.Message+"; Warning: Failed to push metrics: "+err.Error(), "; ")
+		}
+	}
+
+	resp.Outputs = filterOutputs(resp.Outputs, cfg.Outputs)
+	return resp, nil
+}
+
+// downgradeFailure turns a failed response into a successful one for
+// best-effort mode, keeping the error in the "errors" output.
+func downgradeFailure(resp *plugin.ExecuteResponse) *plugin.ExecuteResponse {
+	outputs := resp.Outputs
+	if outputs == nil {
+		outputs = make(map[string]any)
+	}
+	outputs["errors"] = []string{resp.Error}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: fmt.Sprintf("Warning: %s (best_effort: release continues, but Sentry may be missing this release's data)", resp.Error),
+		Outputs: outputs,
+	}
+}
+
+// execute dispatches the request to the handler for its hook.
+func (p *SentryPlugin) execute(ctx context.Context, cfg *Config, req plugin.ExecuteRequest) (*plugin.ExecuteResponse, error) {
+	// Builds without the token secret, such as fork PRs, skip Sentry entirely
+	if cfg.AuthToken == "" && cfg.SkipIfNoToken {
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: "Skipped: no auth token",
+			Outputs: map[string]any{
+				"skipped_no_token": true,
+			},
+		}, nil
+	}
+
+	// Fail fast on invalid config instead of surfacing an opaque API error.
+	// OnError always runs, so an invalid setting cannot block its rollback.
+	if cfg.ValidateOnExecute && req.Hook != plugin.HookOnError {
+		vb, _ := p.validateLocal(req.Config, cfg)
+		if resp := vb.Build(); !resp.Valid {
+			problems := make([]string, 0, len(resp.Errors))
+			for _, e := range resp.Errors {
+				problems = append(problems, fmt.Sprintf("%s: %s", e.Field, e.Message))
+			}
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid configuration: %s", strings.Join(problems, "; ")),
+				Outputs: map[string]any{
+					"validation_errors": resp.Errors,
+				},
+			}, nil
+		}
+	}
+
+	if cfg.previousVersionErr != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   cfg.previousVersionErr.Error(),
+		}, nil
+	}
+	if cfg.artifactChecksumsErr != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   cfg.artifactChecksumsErr.Error(),
+		}, nil
+	}
+	if cfg.releasePrefixErr != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   cfg.releasePrefixErr.Error(),
+		}, nil
+	}
+	if cfg.caCertErr != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   cfg.caCertErr.Error(),
+		}, nil
+	}
+	if cfg.proxyErr != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid proxy_url: %v", cfg.proxyErr),
+		}, nil
+	}
+	if cfg.PreviousVersion != "" {
+		req.Context.PreviousVersion = cfg.PreviousVersion
+	}
+	if err := cfg.renderEnvironments(req.Context); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to render environment: %v", err),
+		}, nil
+	}
+	if cfg.missingEnvironment() {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   "No deploy environment resolved for this build and require_explicit_environment is set",
+		}, nil
+	}
+
+	// Skip entirely when none of the watched paths changed
+	if len(cfg.OnlyIfChanged) > 0 {
+		relevant, err := p.hasRelevantChanges(ctx, cfg, req.Context)
+		if err != nil {
+			cfg.onlyIfChangedErr = err
+		} else if !relevant {
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: "Skipped: no changes matching only_if_changed",
+				Outputs: map[string]any{
+					"skipped_no_relevant_changes": true,
+				},
+			}, nil
+		}
+	}
+
+	// Skip releases with too few commits; OnError still runs so rollbacks happen
+	if cfg.MinCommits > 0 && req.Hook != plugin.HookOnError {
+		if count := len(collectCommits(req.Context.Changes)); count < cfg.MinCommits {
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: fmt.Sprintf("Skipped: %d commits, fewer than min_commits (%d)", count, cfg.MinCommits),
+				Outputs: map[string]any{
+					"commit_count":        count,
+					"skipped_min_commits": true,
+				},
+			}, nil
+		}
+	}
+
+	// Refuse to touch an organization other than the intended one
+	if cfg.ExpectedOrgID != "" && !req.DryRun {
+		if err := checkOrgID(ctx, cfg.newClient(), cfg.ExpectedOrgID); err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
+	}
+
+	// Projects with their own version_format may need separate releases
+	if len(cfg.ProjectVersionFormats) > 0 {
+		groups, err := p.versionGroups(cfg, req.Context)
+		var collisions *versionCollisionError
+		switch {
+		case errors.As(err, &collisions):
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Projects would overwrite each other's release: %s", strings.Join(collisions.Collisions, "; ")),
+			}, nil
+		case err != nil:
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to format version: %v", err),
+			}, nil
+		}
+		if len(groups) > 1 {
+			return p.runVersionGroups(ctx, cfg, req, groups)
+		}
+		if len(groups) == 1 {
+			cfg.VersionFormat = groups[0].Format
+		}
+	}
+
+	return p.runHook(ctx, cfg, req)
+}
+
+// runHook runs the handler for the request's hook and adds the rendered
+// release version to its outputs as release_version.
+func (p *SentryPlugin) runHook(ctx context.Context, cfg *Config, req plugin.ExecuteRequest) (*plugin.ExecuteResponse, error) {
+	var resp *plugin.ExecuteResponse
+	var err error
 	switch req.Hook {
 	case plugin.HookPrePublish:
-		return p.handlePrePublish(ctx, cfg, req.Context, req.DryRun)
+		resp, err = p.handlePrePublish(ctx, cfg, req.Context, req.DryRun)
 	case plugin.HookPostPublish:
-		return p.handlePostPublish(ctx, cfg, req.Context, req.DryRun)
+		resp, err = p.handlePostPublish(ctx, cfg, req.Context, req.DryRun)
 	case plugin.HookOnError:
-		return p.handleOnError(ctx, cfg, req.Context, req.DryRun)
+		resp, err = p.handleOnError(ctx, cfg, req.Context, req.DryRun)
 	default:
 		return &plugin.ExecuteResponse{
 			Success: true,
 			Message: fmt.Sprintf("Hook %s not implemented", req.Hook),
 		}, nil
 	}
-}
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	if version, err := p.formatVersion(cfg.VersionFormat, cfg.versionVars(), req.Context); err == nil {
+		if resp.Outputs == nil {
+			resp.Outputs = make(map[string]any)
+		}
+		resp.Outputs["release_version"] = version
+	}
+	return resp, nil
+}
+
+// checkOrgID verifies that the configured organization has the expected ID,
+// guarding against a copied config or a slug that names another org.
+func checkOrgID(ctx context.Context, client *SentryClient, expected string) error {
+	org, err := client.GetOrganization(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to verify organization against expected_org_id: %w", err)
+	}
+	if org.ID != expected {
+		return errors.New(orgMismatchMessage(client.org, org.ID, expected))
+	}
+	return nil
+}
+
+// orgCheckError returns the field and message reporting a failure to read
+// the organization, telling a missing organization apart from a rejected
+// token.
+func orgCheckError(org string, err error) (string, string) {
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return "auth_token", fmt.Sprintf("Sentry rejected the auth token: %v", err)
+	case errors.Is(err, ErrNotFound):
+		return "org", fmt.Sprintf("Organization %q not found; check the org slug in Sentry under Organization Settings", org)
+	}
+	return "auth_token", fmt.Sprintf("Failed to authenticate with Sentry: %v", err)
+}
+
+// orgMismatchMessage describes an organization whose ID is not the expected
+// one.
+func orgMismatchMessage(slug, id, expected string) string {
+	return fmt.Sprintf("Organization %q has ID %s, but expected_org_id is %s; refusing to publish to the wrong organization", slug, id, expected)
+}
+
+// Validate validates the plugin configuration.
+func (p *SentryPlugin) Validate(ctx context.Context, config map[string]any) (*plugin.ValidateResponse, error) {
+	cfg := p.parseConfig(config)
+	vb, warnings := p.validateLocal(config, cfg)
+
+	// Test API connectivity if auth token is provided
+	if cfg.AuthToken != "" && cfg.Org != "" {
+		client := cfg.newClient()
+		if org, err := client.GetOrganization(ctx); err != nil {
+			vb.AddError(orgCheckError(cfg.Org, err))
+		} else {
+			if cfg.ExpectedOrgID != "" && org.ID != cfg.ExpectedOrgID {
+				vb.AddError("expected_org_id", orgMismatchMessage(cfg.Org, org.ID, cfg.ExpectedOrgID))
+			}
+
+			// Verify projects and team selectors refer to existing ones
+			for _, project := range cfg.getProjects() {
+				if team, ok := teamFromSelector(project); ok {
+					if _, err := client.GetTeam(ctx, team); err != nil {
+						vb.AddError("projects", fmt.Sprintf("Team %q not found: %v", team, err))
+					}
+					continue
+				}
+				if project == allProjectsSelector {
+					continue
+				}
+				switch _, err := client.GetProject(ctx, project); {
+				case errors.Is(err, ErrNotFound):
+					vb.AddError("project", fmt.Sprintf("Project %q not found in organization %q", project, cfg.Org))
+				case errors.Is(err, ErrForbidden):
+					vb.AddError("project", fmt.Sprintf("Auth token cannot access project %q: %v", project, err))
+				}
+			}
+			if cfg.ValidateEnvironment {
+				warnings = append(warnings, unknownEnvironmentWarnings(ctx, client, cfg)...)
+			}
+			if cfg.ValidateRepository {
+				warnings = append(warnings, unregisteredRepositoryWarnings(ctx, client, cfg)...)
+			}
+		}
+	}
+
+	return withWarnings(vb.Build(), warnings), nil
+}
+
+// unknownEnvironmentWarnings warns about deploy environments that none of
+// the configured projects has seen, which usually means a typo such as
+// "prod" for "production". Templated environments and those listed in
+// allow_new_environments are not checked.
+func unknownEnvironmentWarnings(ctx context.Context, client *SentryClient, cfg *Config) []plugin.ValidationError {
+	known := make(map[string]bool)
+	var names []string
+	for _, project := range cfg.getProjects() {
+		if _, ok := teamFromSelector(project); ok || project == allProjectsSelector {
+			continue
+		}
+		environments, err := client.ListEnvironments(ctx, project)
+		if err != nil {
+			return []plugin.ValidationError{{
+				Field:   "deploy.environment",
+				Message: fmt.Sprintf("Could not list environments for project %s: %v", project, err),
+				Code:    validationWarningCode,
+			}}
+		}
+		for _, env := range environments {
+			if !known[env.Name] {
+				known[env.Name] = true
+				names = append(names, env.Name)
+			}
+		}
+	}
+
+	var warnings []plugin.ValidationError
+	for _, env := range cfg.Deploy.environments() {
+		if known[env] || strings.Contains(env, "{{") || slices.Contains(cfg.AllowNewEnvironments, env) {
+			continue
+		}
+		warnings = append(warnings, plugin.ValidationError{
+			Field:   "deploy.environment",
+			Message: fmt.Sprintf("Environment %q is not known to Sentry (known: %s); add it to allow_new_environments if it is new", env, strings.Join(names, ", ")),
+			Code:    validationWarningCode,
+		})
+	}
+	return warnings
+}
+
+// validateLocal runs the validation checks that need no network access:
+// required fields, templates, and value formats.
+func (p *SentryPlugin) validateLocal(config map[string]any, cfg *Config) (*helpers.ValidationBuilder, []plugin.ValidationError) {
+	vb := helpers.NewValidationBuilder()
+	config, _ = migrateDeprecatedKeys(config)
+
+	// Validate auth token
+	if cfg.AuthToken == "" {
+		if !cfg.SkipIfNoToken {
+			vb.AddError("auth_token", "Sentry auth token is required")
+		}
+		return vb, nil
+	}
+
+	// Validate organization
+	if cfg.Org == "" {
+		vb.AddError("org", "Sentry organization is required")
+	}
+
+	// Validate projects
+	var warnings []plugin.ValidationError
+	projects := cfg.getProjects()
+	if len(projects) == 0 {
+		vb.AddError("project", "At least one project is required")
+	}
+	if duplicates := cfg.duplicateProjects(); len(duplicates) > 0 {
+		warnings = append(warnings, plugin.ValidationError{
+			Field:   "projects",
+			Message: fmt.Sprintf("Duplicate projects are ignored: %s", strings.Join(duplicates, ", ")),
+			Code:    validationWarningCode,
+		})
+	}
+
+	warnings = append(warnings, deprecationWarnings(cfg.deprecatedKeys)...)
+	if cfg.InsecureSkipVerify {
+		warnings = append(warnings, plugin.ValidationError{
+			Field:   "insecure_skip_verify",
+			Message: insecureSkipVerifyWarning,
+			Code:    validationWarningCode,
+		})
+	}
+
+	// Warn about booleans that would silently fall back to their defaults
+	commitsRaw, _ := config["commits"].(map[string]any)
+	for _, b := range []struct {
+		raw        map[string]any
+		key, field string
+		defaultVal bool
+	}{
+		{config, "set_commits", "set_commits", true},
+		{config, "create_deploy", "create_deploy", true},
+		{config, "finalize", "finalize", true},
+		{config, "upload_sourcemaps", "upload_sourcemaps", false},
+		{commitsRaw, "auto", "commits.auto", false},
+	} {
+		if warning := validateBool(b.raw, b.key, b.field, b.defaultVal); warning != nil {
+			warnings = append(warnings, *warning)
+		}
+	}
+
+	// Validate version format template
+	if cfg.VersionFormat != "" {
+		_, err := newTemplate("", cfg.VersionFormat)
+		if err != nil {
+			vb.AddError("version_format", fmt.Sprintf("Invalid version format template: %v", err))
+		} else if sample, err := p.formatVersion(cfg.VersionFormat, cfg.versionVars(), sampleReleaseContext); err == nil {
+			if err := checkVersionLength(sample); err != nil {
+				vb.AddError("version_format", fmt.Sprintf("Version format renders too long a version for a sample release: %v", err))
+			}
+			if cfg.RequireSemver && !isSemver(sample) {
+				vb.AddError("version_format", fmt.Sprintf("require_semver is set but version format renders %q for a sample release, which is not a semantic version", sample))
+			}
+		}
+	}
+
+	// Validate per-project version formats, which must not give two
+	// projects' separate releases the same name. The releases are grouped
+	// for a sample release exactly as Execute groups them.
+	if len(cfg.ProjectVersionFormats) > 0 {
+		check := *cfg
+		check.Project = ""
+		check.Projects = nil
+		for _, project := range cfg.getProjects() {
+			if format, ok := cfg.ProjectVersionFormats[project]; ok {
+				if _, err := newTemplate("", format); err != nil {
+					vb.AddError("projects", fmt.Sprintf("Invalid version format template for project %s: %v", project, err))
+					continue
+				}
+			}
+			check.Projects = append(check.Projects, project)
+		}
+		var collisions *versionCollisionError
+		if _, err := p.versionGroups(&check, sampleReleaseContext); errors.As(err, &collisions) {
+			for _, collision := range collisions.Collisions {
+				vb.AddError("projects", fmt.Sprintf("Projects would overwrite each other's release: %s", collision))
+			}
+		}
+	}
+
+	// Validate the source map directory
+	if cfg.UploadSourcemaps {
+		if info, err := os.Stat(cfg.Sourcemaps.Path); err != nil {
+			vb.AddError("sourcemaps.path", fmt.Sprintf("Source map directory %s cannot be read: %v", cfg.Sourcemaps.Path, err))
+		} else if !info.IsDir() {
+			vb.AddError("sourcemaps.path", fmt.Sprintf("Source map path %s is not a directory", cfg.Sourcemaps.Path))
+		}
+	}
+
+	// Validate source map content types
+	for ext, ct := range cfg.Sourcemaps.ContentTypes {
+		if _, _, err := mime.ParseMediaType(ct); err != nil {
+			vb.AddError("sourcemaps.content_types", fmt.Sprintf("Invalid content type %q for %s: %v", ct, ext, err))
+		}
+	}
+
+	// Validate durations
+	validateDuration(vb, config, "fan_out_stagger", "fan_out_stagger")
+	if sourcemaps, ok := config["sourcemaps"].(map[string]any); ok {
+		validatePositiveDuration(vb, sourcemaps, "prune_older_than", "sourcemaps.prune_older_than")
+	}
+	if deploy, ok := config["deploy"].(map[string]any); ok {
+		validateDuration(vb, deploy, "healthcheck_timeout", "deploy.healthcheck_timeout")
+	}
+	if onError, ok := config["on_error"].(map[string]any); ok {
+		validateDuration(vb, onError, "rollback_delay", "on_error.rollback_delay")
+	}
+	if timeouts, ok := config["timeouts"].(map[string]any); ok {
+		for category := range timeouts {
+			if !slices.Contains(timeoutCategories, category) {
+				vb.AddError("timeouts", fmt.Sprintf("Unknown timeout category %q; must be one of: %s", category, strings.Join(timeoutCategories, ", ")))
+				continue
+			}
+			validateDuration(vb, timeouts, category, "timeouts."+category)
+		}
+	}
+
+	// Validate fan-out concurrency limits
+	if c := cfg.Concurrency; c.Initial < 1 || c.Min < 1 || c.Max < 1 {
+		vb.AddError("concurrency", "Concurrency limits must be at least 1")
+	} else if c.Min > c.Max || c.Initial < c.Min || c.Initial > c.Max {
+		vb.AddError("concurrency", "Concurrency limits must satisfy min <= initial <= max")
+	}
+
+	// Validate that an environment is configured when there is no default
+	if cfg.missingEnvironment() {
+		vb.AddError("environment", "An environment is required when require_explicit_environment is set; set environment or deploy.environment")
+	}
+
+	// Validate environment templates
+	checkedEnvs := make(map[string]bool)
+	for _, env := range append([]string{cfg.Environment, cfg.Deploy.Environment}, cfg.Deploy.Environments...) {
+		if strings.Contains(env, "{{") && !checkedEnvs[env] {
+			checkedEnvs[env] = true
+			if _, err := newTemplate("environment", env); err != nil {
+				vb.AddError("environment", fmt.Sprintf("Invalid environment template %q: %v", env, err))
+			}
+		}
+	}
+
+	// Validate deploy name template
+	if cfg.Deploy.NameDefault != "" {
+		if _, err := newTemplate("deploy.name_default", cfg.Deploy.NameDefault); err != nil {
+			vb.AddError("deploy.name_default", fmt.Sprintf("Invalid deploy name template: %v", err))
+		}
+	}
+
+	// Validate repository template
+	if strings.Contains(cfg.Commits.Repository, "{{") {
+		if _, err := newTemplate("repository", cfg.Commits.Repository); err != nil {
+			vb.AddError("commits.repository", fmt.Sprintf("Invalid repository template: %v", err))
+		}
+	}
+
+	// Validate issue key pattern
+	if cfg.AssociateIssues {
+		if _, err := regexp.Compile(cfg.IssuePattern); err != nil {
+			vb.AddError("issue_pattern", fmt.Sprintf("Invalid issue pattern: %v", err))
+		}
+	}
+
+	validateRetryConfig(vb, config)
+
+	// Validate minimum commit count
+	if cfg.MinCommits < 0 {
+		vb.AddError("min_commits", "Minimum commit count must not be negative")
+	}
+
+	// Validate commit hash length
+	if cfg.CommitHashLength != 0 && (cfg.CommitHashLength < 4 || cfg.CommitHashLength > 40) {
+		vb.AddError("commit_hash_length", "Commit hash length must be between 4 and 40, or 0 for full hashes")
+	}
+
+	// Validate short SHA length
+	if cfg.ShortSHALength < 4 || cfg.ShortSHALength > 40 {
+		vb.AddError("short_sha_length", "Short SHA length must be between 4 and 40")
+	}
+
+	// Validate released release policy
+	vb.ValidateOneOf(config, "released_release_policy", []string{releasedPolicySkip, releasedPolicyFail, releasedPolicyRecreate})
+
+	// Validate commits scope
+	if scope := cfg.Commits.Scope; scope != commitsScopeRelease && scope != commitsScopeDeploy {
+		vb.AddError("commits.scope", fmt.Sprintf("Commits scope must be one of: %s, %s", commitsScopeRelease, commitsScopeDeploy))
+	}
+
+	// Validate on-error actions
+	for _, action := range cfg.OnError.Actions {
+		if action != onErrorDeleteRelease && action != onErrorArchiveRelease {
+			vb.AddError("on_error.actions", fmt.Sprintf("Unknown on-error action %q; must be one of: %s, %s", action, onErrorDeleteRelease, onErrorArchiveRelease))
+		}
+	}
+
+	// Validate empty commit message policy
+	if policy := cfg.Commits.EmptyMessagePolicy; policy != emptyMessageSend && policy != emptyMessageSkip && policy != emptyMessagePlaceholder {
+		vb.AddError("commits.empty_message_policy", fmt.Sprintf("Empty message policy must be one of: %s, %s, %s", emptyMessageSend, emptyMessageSkip, emptyMessagePlaceholder))
+	}
+
+	// Validate explicit commit range
+	for _, c := range []struct{ field, sha string }{
+		{"commits.from", cfg.Commits.From},
+		{"commits.to", cfg.Commits.To},
+	} {
+		if c.sha != "" && !commitSHAPattern.MatchString(c.sha) {
+			vb.AddError(c.field, fmt.Sprintf("Commit %q is not a commit SHA (7 to 64 hex characters)", c.sha))
+		}
+	}
+
+	// Validate client-side rate limit
+	if cfg.RateLimit < 0 {
+		vb.AddError("rate_limit", "Rate limit must be a positive number of requests per second, or 0 for unlimited")
+	}
+
+	// Validate commit sort order
+	if order := cfg.Commits.Sort; order != commitsSortCategory && order != commitsSortType && order != commitsSortChronological && order != commitsSortAsIs {
+		vb.AddError("commits.sort", fmt.Sprintf("Commit sort must be one of: %s, %s, %s, %s", commitsSortCategory, commitsSortType, commitsSortChronological, commitsSortAsIs))
+	}
+
+	// Validate previous version file
+	if cfg.previousVersionErr != nil {
+		vb.AddError("previous_version_file", cfg.previousVersionErr.Error())
+	}
+
+	// Validate artifact checksums file
+	if cfg.artifactChecksumsErr != nil {
+		vb.AddError("artifact_checksums_file", cfg.artifactChecksumsErr.Error())
+	}
+
+	// Validate package.json release prefix
+	if cfg.releasePrefixErr != nil {
+		vb.AddError("package_json_path", cfg.releasePrefixErr.Error())
+	}
+
+	// Validate CA certificate file
+	if cfg.caCertErr != nil {
+		vb.AddError("ca_cert_path", cfg.caCertErr.Error())
+	}
+
+	// Validate project success threshold
+	if _, err := requiredProjects(cfg.MinSuccessfulProjects, len(projects)); err != nil {
+		vb.AddError("min_successful_projects", err.Error())
+	}
+
+	// Validate deploy environments are distinct
+	seenEnvs := make(map[string]bool)
+	for _, env := range cfg.Deploy.Environments {
+		if seenEnvs[env] {
+			vb.AddError("deploy.environments", fmt.Sprintf("Duplicate deploy environment: %s", env))
+		}
+		seenEnvs[env] = true
+	}
+
+	// Validate metrics Pushgateway URL
+	if cfg.Metrics.PushgatewayURL != "" {
+		if u, err := url.ParseRequestURI(cfg.Metrics.PushgatewayURL); err != nil || u.Host == "" {
+			vb.AddError("metrics.pushgateway_url", "Pushgateway URL must be a valid URL")
+		}
+	}
+
+	// Validate fallback Sentry URL
+	if cfg.FallbackURL != "" {
+		if u, err := url.ParseRequestURI(cfg.FallbackURL); err != nil || u.Host == "" {
+			vb.AddError("fallback_url", "Fallback URL must be a valid URL")
+		}
+	}
+
+	// Validate finalize webhook URL
+	if cfg.FinalizeWebhook.URL != "" {
+		if u, err := url.ParseRequestURI(cfg.FinalizeWebhook.URL); err != nil || u.Host == "" {
+			vb.AddError("finalize_webhook", "Finalize webhook URL must be a valid URL")
+		}
+	}
+
+	// Validate release webhook URL
+	if cfg.ReleaseWebhook.URL != "" {
+		if u, err := url.ParseRequestURI(cfg.ReleaseWebhook.URL); err != nil || u.Host == "" {
+			vb.AddError("release_webhook", "Release webhook URL must be a valid URL")
+		}
+	}
+
+	// Validate proxy URL
+	if cfg.proxyErr != nil {
+		vb.AddError("proxy_url", cfg.proxyErr.Error())
+	}
+
+	// Validate TLS server name
+	if cfg.TLSServerName != "" && !hostnamePattern.MatchString(cfg.TLSServerName) {
+		vb.AddError("tls_server_name", fmt.Sprintf("Invalid TLS server name: %q is not a valid hostname", cfg.TLSServerName))
+	}
+
+	return vb, warnings
+}
+
+// withWarnings appends non-fatal findings to a validation response. They
+// carry the warning code and do not affect Valid.
+func withWarnings(resp *plugin.ValidateResponse, warnings []plugin.ValidationError) *plugin.ValidateResponse {
+	resp.Errors = append(resp.Errors, warnings...)
+	return resp
+}
+
+// filterOutputs keeps only the listed output keys. An empty list keeps all outputs.
+func filterOutputs(outputs map[string]any, keys []string) map[string]any {
+	if len(keys) == 0 || outputs == nil {
+		return outputs
+	}
+	filtered := make(map[string]any, len(keys))
+	for _, k := range keys {
+		if v, ok := outputs[k]; ok {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+// hasRelevantChanges reports whether any file changed since the previous
+// release matches the only_if_changed globs.
+func (p *SentryPlugin) hasRelevantChanges(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext) (bool, error) {
+	from := previousTag(releaseCtx)
+	if from == "" {
+		return false, fmt.Errorf("previous version is unknown")
+	}
+	to := releaseCtx.CommitSHA
+	if to == "" {
+		to = "HEAD"
+	}
+
+	files, err := changedFiles(ctx, from, to)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range files {
+		if matchAnyGlob(cfg.OnlyIfChanged, f) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// previousTag derives the previous release tag using the current tag's
+// prefix, e.g. tag "v1.2.3" with previous version "1.2.2" yields "v1.2.2".
+func previousTag(releaseCtx plugin.ReleaseContext) string {
+	if releaseCtx.PreviousVersion == "" {
+		return ""
+	}
+	prefix := ""
+	if strings.HasSuffix(releaseCtx.TagName, releaseCtx.Version) {
+		prefix = strings.TrimSuffix(releaseCtx.TagName, releaseCtx.Version)
+	}
+	return prefix + releaseCtx.PreviousVersion
+}
+
+// parseConfig parses and applies defaults to the configuration.
+func (p *SentryPlugin) parseConfig(raw map[string]any) *Config {
+	raw, deprecated := migrateDeprecatedKeys(raw)
+	parser := helpers.NewConfigParser(raw)
+
+	cfg := &Config{
+		AuthToken:                 parser.GetString("auth_token", "SENTRY_AUTH_TOKEN", ""),
+		Org:                       parser.GetString("org", "SENTRY_ORG", ""),
+		Project:                   parser.GetString("project", "SENTRY_PROJECT", ""),
+		URL:                       parser.GetString("url", "SENTRY_URL", "https://sentry.io"),
+		VersionFormat:             parser.GetString("version_format", "", "{{.Version}}"),
+		Environment:               parser.GetString("environment", "", "production"),
+		SetCommits:                getBool(raw, "set_commits", true),
+		CreateDeploy:              getBool(raw, "create_deploy", true),
+		UploadSourcemaps:          getBool(raw, "upload_sourcemaps", false),
+		Finalize:                  getBool(raw, "finalize", true),
+		CIMetadata:                parser.GetBool("ci_metadata", true),
+		Summary:                   parser.GetBool("summary", false),
+		TLSServerName:             parser.GetString("tls_server_name", "", ""),
+		OnlyIfChanged:             parser.GetStringSlice("only_if_changed", nil),
+		AssociateIssues:           parser.GetBool("associate_issues", false),
+		IssuePattern:              parser.GetString("issue_pattern", "", defaultIssuePattern),
+		CommitHashLength:          parser.GetInt("commit_hash_length", 0),
+		ShortSHALength:            parser.GetInt("short_sha_length", defaultShortSHALength),
+		FanOutStagger:             getDuration(raw, "fan_out_stagger", defaultFanOutStagger),
+		ReleasedReleasePolicy:     parser.GetString("released_release_policy", "", releasedPolicySkip),
+		Outputs:                   parser.GetStringSlice("outputs", nil),
+		BestEffort:                parser.GetBool("best_effort", false),
+		EnrichCommitsFromGit:      parser.GetBool("enrich_commits_from_git", false),
+		SkipFinalizeForPrerelease: parser.GetBool("skip_finalize_for_prerelease", false),
+		SkipIfNoToken:             parser.GetBool("skip_if_no_token", false),
+		ChannelMetadata:           parser.GetBool("channel_metadata", false),
+		Audit:                     parser.GetBool("audit", false),
+		MinCommits:                parser.GetInt("min_commits", 0),
+		Retry:                     parseRetryConfig(raw),
+		RequireSemver:             parser.GetBool("require_semver", false),
+		AdoptExisting:             parser.GetBool("adopt_existing", false),
+		ExportConfig:              parser.GetBool("export_config", false),
+		FinalizeWebhook:           parseWebhookConfig(raw["finalize_webhook"]),
+		ReleaseWebhook:            parseWebhookConfig(raw["release_webhook"]),
+		ValidateOnExecute:         parser.GetBool("validate_on_execute", false),
+		TagAnnotation:             parser.GetBool("tag_annotation", false),
+		ParallelSteps:             parser.GetBool("parallel_steps", false),
+		ProtectFinalized:          parser.GetBool("protect_finalized", false),
+		Force:                     parser.GetBool("force", false),
+		GitHubReleaseURL:          parser.GetBool("github_release_url", false),
+		ValidateEnvironment:       parser.GetBool("validate_environment", false),
+		AllowNewEnvironments:      parser.GetStringSlice("allow_new_environments", nil),
+		ValidateRepository:        parser.GetBool("validate_repository", false),
+		ListRepositories:          parser.GetBool("list_repositories", false),
+		RateLimit:                 parser.GetFloat("rate_limit", 0),
+		FallbackURL:               parser.GetString("fallback_url", "SENTRY_FALLBACK_URL", ""),
+		ProxyURL:                  strings.TrimSpace(parser.GetString("proxy_url", "", "")),
+		CACertPath:                parser.GetString("ca_cert_path", "", ""),
+		InsecureSkipVerify:        parser.GetBool("insecure_skip_verify", false),
+		Debug:                     parser.GetBool("debug", false),
+	}
+	cfg.RequireExplicitEnvironment = parser.GetBool("require_explicit_environment", false)
+	if cfg.RequireExplicitEnvironment {
+		cfg.Environment = parser.GetString("environment", "", "")
+	}
+
+	// Read previous version from file
+	cfg.PreviousVersionFile = parser.GetString("previous_version_file", "", "")
+	if cfg.PreviousVersionFile != "" {
+		data, err := os.ReadFile(cfg.PreviousVersionFile)
+		if err != nil {
+			cfg.previousVersionErr = fmt.Errorf("failed to read previous version file: %w", err)
+		} else {
+			cfg.PreviousVersion = strings.TrimSpace(string(data))
+		}
+	}
+
+	// Prefix release versions, e.g. "my-app@1.2.3", optionally with the
+	// package name from package.json
+	cfg.ReleasePrefix = parser.GetString("release_prefix", "", "")
+	cfg.ReleasePrefixFromPackageJSON = parser.GetBool("release_prefix_from_package_json", false)
+	cfg.PackageJSONPath = parser.GetString("package_json_path", "", defaultPackageJSONPath)
+	if cfg.ReleasePrefixFromPackageJSON {
+		if name, err := readPackageName(cfg.PackageJSONPath); err != nil {
+			cfg.releasePrefixErr = err
+		} else {
+			cfg.ReleasePrefix = name + "@"
+		}
+	}
+	cfg.VersionFormat = cfg.ReleasePrefix + cfg.VersionFormat
+
+	// Trust the CA bundle of a self-hosted Sentry
+	if cfg.CACertPath != "" {
+		cfg.rootCAs, cfg.caCertErr = readCACertPool(cfg.CACertPath)
+	}
+	if cfg.ProxyURL != "" {
+		_, cfg.proxyErr = parseProxyURL(cfg.ProxyURL)
+	}
+
+	// Read artifact checksums, with explicit entries overriding the file
+	cfg.ArtifactChecksumsFile = parser.GetString("artifact_checksums_file", "", "")
+	if cfg.ArtifactChecksumsFile != "" {
+		cfg.ArtifactChecksums, cfg.artifactChecksumsErr = readChecksumFile(cfg.ArtifactChecksumsFile)
+	}
+	for name, sum := range parser.GetMap("artifact_checksums") {
+		if s, ok := sum.(string); ok {
+			if cfg.ArtifactChecksums == nil {
+				cfg.ArtifactChecksums = make(map[string]string)
+			}
+			cfg.ArtifactChecksums[name] = s
+		}
+	}
+
+	// Parse the expected organization ID, given as a string or a number
+	if v, ok := raw["expected_org_id"]; ok && v != nil {
+		cfg.ExpectedOrgID = strings.TrimSpace(fmt.Sprint(v))
+	}
+
+	// Parse project success threshold, given as a count or a percentage
+	if v, ok := raw["min_successful_projects"]; ok && v != nil {
+		cfg.MinSuccessfulProjects = strings.TrimSpace(fmt.Sprint(v))
+	}
+
+	// Parse projects array, or a single selector such as "team:my-team".
+	// Entries may be objects with a slug and their own version_format.
+	switch projects := raw["projects"].(type) {
+	case []any:
+		for _, p := range projects {
+			switch entry := p.(type) {
+			case string:
+				cfg.Projects = append(cfg.Projects, entry)
+			case map[string]any:
+				entryParser := helpers.NewConfigParser(entry)
+				slug := strings.TrimSpace(entryParser.GetString("slug", "", ""))
+				if slug == "" {
+					continue
+				}
+				cfg.Projects = append(cfg.Projects, slug)
+				if format := entryParser.GetString("version_format", "", ""); format != "" {
+					if cfg.ProjectVersionFormats == nil {
+						cfg.ProjectVersionFormats = make(map[string]string)
+					}
+					cfg.ProjectVersionFormats[slug] = cfg.ReleasePrefix + format
+				}
+			}
+		}
+	case string:
+		if projects != "" {
+			cfg.Projects = []string{projects}
+		}
+	}
+
+	// Parse commits config
+	if commits, ok := raw["commits"].(map[string]any); ok {
+		commitParser := helpers.NewConfigParser(commits)
+		cfg.Commits = CommitsConfig{
+			Auto:       getBool(commits, "auto", false),
+			Repository: commitParser.GetString("repository", "", ""),
+			Scope:      commitParser.GetString("scope", "", commitsScopeRelease),
+		}
+		cfg.Commits.EmptyMessagePolicy = commitParser.GetString("empty_message_policy", "", emptyMessageSend)
+		cfg.Commits.Sort = commitParser.GetString("sort", "", commitsSortCategory)
+		cfg.Commits.IncludeTypeScope = commitParser.GetBool("include_type_scope", false)
+		cfg.Commits.From = strings.TrimSpace(commitParser.GetString("from", "", ""))
+		cfg.Commits.To = strings.TrimSpace(commitParser.GetString("to", "", ""))
+		for scope, repo := range commitParser.GetMap("scope_repositories") {
+			if s, ok := repo.(string); ok && s != "" {
+				if cfg.Commits.ScopeRepositories == nil {
+					cfg.Commits.ScopeRepositories = make(map[string]string)
+				}
+				cfg.Commits.ScopeRepositories[scope] = s
+			}
+		}
+		if transform := commitParser.GetMap("repository_transform"); transform != nil {
+			transformParser := helpers.NewConfigParser(transform)
+			cfg.Commits.RepositoryTransform.StripPrefix = transformParser.GetStringSlice("strip_prefix", nil)
+			if s := transformParser.GetString("strip_prefix", "", ""); s != "" {
+				cfg.Commits.RepositoryTransform.StripPrefix = []string{s}
+			}
+			for from, to := range transformParser.GetMap("host_map") {
+				if s, ok := to.(string); ok {
+					if cfg.Commits.RepositoryTransform.HostMap == nil {
+						cfg.Commits.RepositoryTransform.HostMap = make(map[string]string)
+					}
+					cfg.Commits.RepositoryTransform.HostMap[from] = s
+				}
+			}
+		}
+	} else {
+		cfg.Commits = CommitsConfig{Scope: commitsScopeRelease, EmptyMessagePolicy: emptyMessageSend, Sort: commitsSortCategory}
+	}
+
+	// Parse fan-out concurrency limits
+	cfg.Concurrency = ConcurrencyConfig{
+		Initial: defaultFanOutConcurrency,
+		Min:     defaultFanOutMinConcurrency,
+		Max:     defaultFanOutMaxConcurrency,
+	}
+	if concurrency, ok := raw["concurrency"].(map[string]any); ok {
+		concurrencyParser := helpers.NewConfigParser(concurrency)
+		cfg.Concurrency.Initial = concurrencyParser.GetInt("initial", cfg.Concurrency.Initial)
+		cfg.Concurrency.Min = concurrencyParser.GetInt("min", cfg.Concurrency.Min)
+		cfg.Concurrency.Max = concurrencyParser.GetInt("max", cfg.Concurrency.Max)
+	}
+
+	cfg.apiTime = &apiTimer{}
+	if cfg.Audit {
+		cfg.audit = newAuditLog(cfg.AuthToken)
+	}
+
+	// Parse prerelease identifier to channel overrides
+	for id, channel := range parser.GetMap("channels") {
+		if s, ok := channel.(string); ok {
+			if cfg.Channels == nil {
+				cfg.Channels = make(map[string]string)
+			}
+			cfg.Channels[strings.ToLower(id)] = s
+		}
+	}
+
+	// Parse per-category request timeouts
+	if timeouts, ok := raw["timeouts"].(map[string]any); ok {
+		for category := range timeouts {
+			if d := getDuration(timeouts, category, 0); d > 0 {
+				if cfg.Timeouts == nil {
+					cfg.Timeouts = make(map[string]time.Duration)
+				}
+				cfg.Timeouts[category] = d
+			}
+		}
+	}
+
+	// Parse metrics config
+	if metrics, ok := raw["metrics"].(map[string]any); ok {
+		metricsParser := helpers.NewConfigParser(metrics)
+		cfg.Metrics = MetricsConfig{
+			PushgatewayURL: metricsParser.GetString("pushgateway_url", "", ""),
+			Job:            metricsParser.GetString("job", "", defaultMetricsJob),
+		}
+		if cfg.Metrics.PushgatewayURL != "" {
+			cfg.metrics = newRunMetrics()
+		}
+	}
+
+	// Parse on-error config
+	cfg.OnError = OnErrorConfig{FailOnError: true}
+	if onError, ok := raw["on_error"].(map[string]any); ok {
+		onErrorParser := helpers.NewConfigParser(onError)
+		cfg.OnError.Actions = onErrorParser.GetStringSlice("actions", nil)
+		cfg.OnError.FailOnError = onErrorParser.GetBool("fail_on_error", true)
+		cfg.OnError.RollbackDelay = getDuration(onError, "rollback_delay", 0)
+	}
+
+	// Parse release config
+	if release, ok := raw["release"].(map[string]any); ok {
+		releaseParser := helpers.NewConfigParser(release)
+		cfg.Release.Ref = releaseParser.GetString("ref", "", "")
+		for k, v := range releaseParser.GetMap("query_params") {
+			if v == nil {
+				continue
+			}
+			if cfg.Release.QueryParams == nil {
+				cfg.Release.QueryParams = make(map[string]string)
+			}
+			cfg.Release.QueryParams[k] = fmt.Sprint(v)
+		}
+	}
+
+	// Parse deploy config
+	if deploy, ok := raw["deploy"].(map[string]any); ok {
+		deployParser := helpers.NewConfigParser(deploy)
+		cfg.Deploy = DeployConfig{
+			Environment: deployParser.GetString("environment", "", cfg.Environment),
+			Name:        deployParser.GetString("name", "", ""),
+		}
+		cfg.Deploy.Environments = deployParser.GetStringSlice("environments", nil)
+		cfg.Deploy.Atomic = deployParser.GetBool("atomic", false)
+		cfg.Deploy.HealthcheckURL = deployParser.GetString("healthcheck_url", "", "")
+		cfg.Deploy.HealthcheckTimeout = getDuration(deploy, "healthcheck_timeout", defaultHealthcheckTimeout)
+		cfg.Deploy.NameDefault = deployParser.GetString("name_default", "", "")
+		cfg.Deploy.CommitRange = deployParser.GetBool("commit_range", false)
+		cfg.Deploy.DeployedBy = deployParser.GetString("deployed_by", "GITHUB_ACTOR", "")
+	} else {
+		cfg.Deploy = DeployConfig{
+			Environment: cfg.Environment,
+			DeployedBy:  os.Getenv("GITHUB_ACTOR"),
+		}
+	}
+
+	// Parse sourcemaps config, applying the defaults when the section is
+	// omitted so upload_sourcemaps works on its own
+	sourcemaps, _ := raw["sourcemaps"].(map[string]any)
+	smParser := helpers.NewConfigParser(sourcemaps)
+	cfg.Sourcemaps = SourcemapsConfig{
+		Path:           smParser.GetString("path", "", defaultSourcemapPath),
+		URLPrefix:      smParser.GetString("url_prefix", "", defaultSourcemapURLPrefix),
+		Prune:          smParser.GetBool("prune", false),
+		PruneOlderThan: getPositiveDuration(sourcemaps, "prune_older_than", defaultPruneOlderThan),
+	}
+	for ext, ct := range smParser.GetMap("content_types") {
+		if s, ok := ct.(string); ok && s != "" {
+			if cfg.Sourcemaps.ContentTypes == nil {
+				cfg.Sourcemaps.ContentTypes = make(map[string]string)
+			}
+			cfg.Sourcemaps.ContentTypes[normalizeExtension(ext)] = s
+		}
+	}
+	if include, ok := sourcemaps["include"].([]any); ok {
+		for _, i := range include {
+			if s, ok := i.(string); ok {
+				cfg.Sourcemaps.Include = append(cfg.Sourcemaps.Include, s)
+			}
+		}
+	}
+	if exclude, ok := sourcemaps["exclude"].([]any); ok {
+		for _, e := range exclude {
+			if s, ok := e.(string); ok {
+				cfg.Sourcemaps.Exclude = append(cfg.Sourcemaps.Exclude, s)
+			}
+		}
+	}
+
+	cfg.deprecatedKeys = deprecated
+	return cfg
+}
+
+// newClient creates a Sentry client from the configuration.
+func (cfg *Config) newClient() *SentryClient {
+	client := NewSentryClient(cfg.URL, cfg.AuthToken, cfg.Org, ClientOptions{
+		TLSServerName:      cfg.TLSServerName,
+		Timeouts:           cfg.Timeouts,
+		Retry:              cfg.Retry,
+		RateLimit:          cfg.RateLimit,
+		FallbackURL:        cfg.FallbackURL,
+		ProxyURL:           cfg.ProxyURL,
+		RootCAs:            cfg.rootCAs,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	})
+	client.metrics = cfg.metrics
+	client.audit = cfg.audit
+	client.apiTime = cfg.apiTime
+	if cfg.Debug {
+		client.debug = newDebugLogger(debugOutput, cfg.AuthToken)
+	}
+	return client
+}
+
+// effectiveJSON returns the resolved config as JSON, with defaults and
+// environment variables applied and the auth token redacted.
+func (cfg *Config) effectiveJSON() string {
+	redacted := *cfg
+	if redacted.AuthToken != "" {
+		redacted.AuthToken = "[REDACTED]"
+	}
+	redacted.URL = redactURL(redacted.URL)
+	redacted.FallbackURL = redactURL(redacted.FallbackURL)
+	redacted.ProxyURL = redactURL(redacted.ProxyURL)
+	redacted.Deploy.HealthcheckURL = redactURL(redacted.Deploy.HealthcheckURL)
+	redacted.Metrics.PushgatewayURL = redactURL(redacted.Metrics.PushgatewayURL)
+	redacted.FinalizeWebhook.URL = redactSecretURL(redacted.FinalizeWebhook.URL)
+	redacted.ReleaseWebhook.URL = redactSecretURL(redacted.ReleaseWebhook.URL)
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return fmt.Sprintf(`{"error": %q}`, err.Error())
+	}
+	return string(data)
+}
+
+// redactURL hides the credentials a URL may carry: its userinfo, such as
+// "user:pass@" in a proxy URL, and its query values.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "[REDACTED]"
+	}
+	if u.User != nil {
+		u.User = url.User("REDACTED")
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for key := range query {
+			query.Set(key, "REDACTED")
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// redactSecretURL keeps only the scheme and host of a URL whose path is
+// itself a secret, as with most webhook URLs.
+func redactSecretURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "[REDACTED]"
+	}
+	return u.Scheme + "://" + u.Host + "/[REDACTED]"
+}
+
+// getProjects returns all configured projects.
+// Duplicates are dropped, keeping the first occurrence.
+func (cfg *Config) getProjects() []string {
+	var projects []string
+	seen := make(map[string]bool)
+	for _, p := range append(append([]string{}, cfg.Projects...), cfg.Project) {
+		if p != "" && !seen[p] {
+			seen[p] = true
+			projects = append(projects, p)
+		}
+	}
+	return projects
+}
+
+// duplicateProjects returns the slugs listed more than once in projects,
+// or listed in both project and projects.
+func (cfg *Config) duplicateProjects() []string {
+	var duplicates []string
+	counts := make(map[string]int)
+	for _, p := range append(append([]string{}, cfg.Projects...), cfg.Project) {
+		if p == "" {
+			continue
+		}
+		counts[p]++
+		if counts[p] == 2 {
+			duplicates = append(duplicates, p)
+		}
+	}
+	return duplicates
+}
+
+// templateFuncs are the functions available in config templates.
+var templateFuncs = template.FuncMap{
+	// dateFormat formats a time with a Go layout, e.g. {{dateFormat "2006.01.02" .Now}}.
+	"dateFormat": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// newTemplate parses a config template with the shared template functions.
+func newTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// renderContextTemplate renders a config value that may contain a template
+// with the release context. Env holds the process environment overlaid with
+// the context's environment, and ShortSHA is shaLength characters long.
+// Values without "{{" are returned unchanged.
+func renderContextTemplate(name, text string, shaLength int, releaseCtx plugin.ReleaseContext) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := newTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	for k, v := range releaseCtx.Environment {
+		env[k] = v
+	}
+
+	data := struct {
+		Version         string
+		TagName         string
+		ShortSHA        string
+		Branch          string
+		RepositoryOwner string
+		RepositoryName  string
+		Env             map[string]string
+	}{
+		Version:         releaseCtx.Version,
+		TagName:         releaseCtx.TagName,
+		ShortSHA:        shortSHA(releaseCtx.CommitSHA, shaLength),
+		Branch:          releaseCtx.Branch,
+		RepositoryOwner: releaseCtx.RepositoryOwner,
+		RepositoryName:  releaseCtx.RepositoryName,
+		Env:             env,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Option("missingkey=zero").Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// versionDateLayout formats {{.Date}} in version templates.
+const versionDateLayout = "20060102"
+
+// versionVars holds the version template values that come from the
+// configuration rather than the release context.
+type versionVars struct {
+	// Channels overrides the prerelease-to-channel mapping for {{.Channel}}.
+	Channels map[string]string
+	// ShortSHALength is the length of {{.ShortSHA}}.
+	ShortSHALength int
+	Environment    string
+	Project        string
+}
+
+// versionVars returns the configuration's version template values.
+// Project is only set when a single project is configured.
+func (cfg *Config) versionVars() versionVars {
+	vars := versionVars{
+		Channels:       cfg.Channels,
+		ShortSHALength: cfg.ShortSHALength,
+		Environment:    cfg.Environment,
+	}
+	if projects := cfg.getProjects(); len(projects) == 1 {
+		vars.Project = projects[0]
+	}
+	return vars
+}
+
+// formatVersion renders the version string using the template.
+func (p *SentryPlugin) formatVersion(format string, vars versionVars, ctx plugin.ReleaseContext) (string, error) {
+	tmpl, err := newTemplate("version", format)
+	if err != nil {
+		return "", err
+	}
+
+	now := timeNow().UTC()
+	data := struct {
+		Version     string
+		TagName     string
+		ShortSHA    string
+		CommitSHA   string
+		Branch      string
+		Channel     string
+		Environment string
+		Project     string
+		Date        string
+		Now         time.Time
+	}{
+		Version:     ctx.Version,
+		TagName:     ctx.TagName,
+		ShortSHA:    shortSHA(ctx.CommitSHA, vars.ShortSHALength),
+		CommitSHA:   ctx.CommitSHA,
+		Branch:      ctx.Branch,
+		Channel:     deriveChannel(ctx.Version, vars.Channels),
+		Environment: vars.Environment,
+		Project:     vars.Project,
+		Date:        now.Format(versionDateLayout),
+		Now:         now,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// checkVersionLength returns an error if the version is longer than Sentry
+// accepts.
+func checkVersionLength(version string) error {
+	if len(version) > maxVersionLength {
+		return fmt.Errorf("version %q is %d characters; Sentry allows at most %d", version, len(version), maxVersionLength)
+	}
+	return nil
+}
+
+// releaseWebURL returns the Sentry web UI URL for a release.
+func releaseWebURL(cfg *Config, version string) string {
+	return fmt.Sprintf("%s/organizations/%s/releases/%s/", strings.TrimSuffix(cfg.URL, "/"), cfg.Org, url.PathEscape(version))
+}
+
+// compareURL returns a link to the release page in the Sentry web UI that
+// compares the release against a previous one.
+func compareURL(cfg *Config, version, previous string, projects []string) string {
+	query := url.Values{}
+	for _, slug := range projects {
+		query.Add("project", slug)
+	}
+	query.Set("compare", previous)
+	return releaseWebURL(cfg, version) + "?" + query.Encode()
+}
+
+// previousReleaseVersion formats the previous version from the release
+// context, returning "" when it is unknown or cannot be formatted.
+func (p *SentryPlugin) previousReleaseVersion(cfg *Config, releaseCtx plugin.ReleaseContext) string {
+	if releaseCtx.PreviousVersion == "" {
+		return ""
+	}
+	prevCtx := releaseCtx
+	prevCtx.Version = releaseCtx.PreviousVersion
+	prevCtx.TagName = previousTag(releaseCtx)
+	prevCtx.CommitSHA = ""
+	previous, err := p.formatVersion(cfg.VersionFormat, cfg.versionVars(), prevCtx)
+	if err != nil {
+		return ""
+	}
+	return previous
+}
+
+// releaseRef returns the head commit to record on the release. A configured
+// ref wins, but a warning is returned when it disagrees with the commit SHA
+// used for commit association.
+func releaseRef(cfg *Config, releaseCtx plugin.ReleaseContext) (string, string) {
+	ref := cfg.Release.Ref
+	if ref == "" {
+		return releaseCtx.CommitSHA, ""
+	}
+	if releaseCtx.CommitSHA != "" && !strings.HasPrefix(releaseCtx.CommitSHA, ref) {
+		return ref, fmt.Sprintf("release.ref %s does not match the release commit %s", ref, releaseCtx.CommitSHA)
+	}
+	return ref, ""
+}
+
+// defaultShortSHALength is how many characters of a SHA are shown when
+// short_sha_length is not set.
+const defaultShortSHALength = 7
+
+// shortSHA returns the first length characters of a SHA, or the first
+// defaultShortSHALength when length is not positive.
+func shortSHA(sha string, length int) string {
+	if length <= 0 {
+		length = defaultShortSHALength
+	}
+	return truncateHash(sha, length)
+}
+
+// handlePrePublish creates the release in Sentry before publishing.
+func (p *SentryPlugin) handlePrePublish(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
+	version, err := p.formatVersion(cfg.VersionFormat, cfg.versionVars(), releaseCtx)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to format version: %v", err),
+		}, nil
+	}
+	if err := checkVersionLength(version); err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid version from version_format: %v", err),
+		}, nil
+	}
+	if cfg.RequireSemver && !isSemver(version) {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid version from version_format: %q is not a semantic version and require_semver is set", version),
+		}, nil
+	}
+
+	projects := cfg.getProjects()
+
+	ref, refWarning := releaseRef(cfg, releaseCtx)
+	opts := ReleaseOptions{Ref: ref, QueryParams: cfg.Release.QueryParams}
+	var ci *CIMetadata
+	if cfg.CIMetadata {
+		ci = detectCI(envLookup(releaseCtx.Environment))
+		if ci != nil {
+			opts.URL = ci.BuildURL
+			opts.VersionInfo = ci.versionInfo()
+		}
+	}
+
+	if len(cfg.ArtifactChecksums) > 0 {
+		opts.VersionInfo = mergeVersionInfo(opts.VersionInfo, checksumVersionInfo(cfg.ArtifactChecksums))
+	}
+
+	// Record the git tag that produced the release
+	var tagWarning string
+	if releaseCtx.TagName != "" {
+		tagInfo := map[string]string{"git_tag": releaseCtx.TagName}
+		if cfg.TagAnnotation {
+			if annotation, err := readTagAnnotation(ctx, releaseCtx.TagName); err != nil {
+				tagWarning = fmt.Sprintf("Failed to read tag annotation: %v", err)
+			} else if annotation != "" {
+				tagInfo["git_tag_message"] = annotation
+			}
+		}
+		opts.VersionInfo = mergeVersionInfo(opts.VersionInfo, tagInfo)
+	}
+
+	if cfg.ChannelMetadata {
+		opts.VersionInfo = mergeVersionInfo(opts.VersionInfo, map[string]string{
+			"channel": deriveChannel(releaseCtx.Version, cfg.Channels),
+		})
+	}
+
+	// Link the Sentry release back to its GitHub release page
+	var githubURL string
+	if cfg.GitHubReleaseURL {
+		if githubURL = githubReleaseURL(envLookup(releaseCtx.Environment), releaseCtx); githubURL != "" {
+			opts.URL = githubURL
+		}
+	}
+
+	var issues []string
+	if cfg.AssociateIssues {
+		pattern, err := regexp.Compile(cfg.IssuePattern)
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid issue pattern: %v", err),
+			}, nil
+		}
+		issues = extractIssueKeys(collectCommits(releaseCtx.Changes), pattern)
+		if len(issues) > 0 {
+			opts.VersionInfo = mergeVersionInfo(opts.VersionInfo, issueVersionInfo(issues))
+		}
+	}
+
+	if dryRun {
+		outputs := map[string]any{"version": version}
+		addProjectOutputs(outputs, projects)
+		if githubURL != "" {
+			outputs["github_release_url"] = githubURL
+		}
+		addCIOutputs(outputs, ci)
+		if len(issues) > 0 {
+			outputs["issues"] = issues
+		}
+		results := []string{fmt.Sprintf("Would create Sentry release '%s' for projects: %s", version, strings.Join(projects, ", "))}
+		if cfg.ReleaseWebhook.URL != "" {
+			results = append(results, "Would send release webhook")
+		}
+		if cfg.UploadSourcemaps {
+			files, err := sourcemapFiles(cfg.Sourcemaps)
+			if err != nil {
+				results = append(results, fmt.Sprintf("Warning: %v", err))
+			} else {
+				results = append(results, fmt.Sprintf("Would upload %d source map files from %s", len(files), cfg.Sourcemaps.Path))
+			}
+		}
+		if cfg.Sourcemaps.Prune {
+			stale, err := listStaleReleaseFiles(ctx, cfg.newClient(), version, cfg.Sourcemaps.PruneOlderThan, nil)
+			switch {
+			case isNotFound(err):
+				results = append(results, "Would prune no release files: release does not exist yet")
+			case err != nil:
+				results = append(results, fmt.Sprintf("Warning: Failed to list release files to prune: %v", err))
+			default:
+				var totalBytes int64
+				for _, f := range stale {
+					totalBytes += f.Size
+				}
+				results = append(results, fmt.Sprintf("Would prune %d release files older than %s (%d bytes)", len(stale), cfg.Sourcemaps.PruneOlderThan, totalBytes))
+				outputs["pruned_files"] = len(stale)
+				outputs["pruned_bytes"] = totalBytes
+			}
+		}
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: strings.Join(results, "; "),
+			Outputs: outputs,
+		}, nil
+	}
+
+	client := cfg.newClient()
+
+	// Resolve project selectors
+	if hasProjectSelectors(projects) {
+		projects, err = resolveProjects(ctx, client, projects)
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to resolve projects: %v", err),
+			}, nil
+		}
+	}
+
+	// Apply the policy for releases that were already finalized
+	existing, err := client.GetRelease(ctx, version)
+	if err != nil {
+		existing = nil
+	}
+	if existing != nil && !existing.DateReleased.IsZero() {
+		switch cfg.ReleasedReleasePolicy {
+		case releasedPolicyFail:
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Release %s was already released at %s", version, existing.DateReleased.Format(time.RFC3339)),
+			}, nil
+		case releasedPolicyRecreate:
+			if cfg.ProtectFinalized && !cfg.Force {
+				return &plugin.ExecuteResponse{
+					Success: false,
+					Error:   fmt.Sprintf("Release %s was finalized at %s; refusing to recreate it (protect_finalized, set force to override)", version, existing.DateReleased.Format(time.RFC3339)),
+					Outputs: map[string]any{
+						"version":   version,
+						"protected": true,
+					},
+				}, nil
+			}
+			if err := client.DeleteRelease(ctx, version); err != nil {
+				return &plugin.ExecuteResponse{
+					Success: false,
+					Error:   fmt.Sprintf("Failed to delete released release for recreation: %v", err),
+				}, nil
+			}
+			existing = nil
+		default:
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: fmt.Sprintf("Sentry release %s was already released; skipping", version),
+				Outputs: map[string]any{
+					"version":          version,
+					"already_released": true,
+				},
+			}, nil
+		}
+	}
+
+	// Create release, per project when a success threshold is configured.
+	// A release created outside the plugin, e.g. by sentry-cli, is adopted
+	// as-is when adopt_existing is set.
+	var release *Release
+	var projectResults map[string]string
+	adopted := cfg.AdoptExisting && existing != nil
+	if adopted {
+		release = existing
+	} else if cfg.MinSuccessfulProjects != "" && len(projects) > 1 {
+		required, err := requiredProjects(cfg.MinSuccessfulProjects, len(projects))
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid min_successful_projects: %v", err),
+			}, nil
+		}
+
+		var succeeded int
+		release, projectResults, succeeded = createProjectReleases(ctx, client, version, projects, opts, cfg.Concurrency, cfg.FanOutStagger)
+		if succeeded < required || release == nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Created release in %d of %d projects; at least %d required", succeeded, len(projects), required),
+				Outputs: map[string]any{
+					"version":         version,
+					"project_results": projectResults,
+				},
+			}, nil
+		}
+	} else {
+		release, err = client.CreateRelease(ctx, version, projects, opts)
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to create release: %v", err),
+			}, nil
+		}
+	}
+
+	outputs := map[string]any{
+		"version":      release.Version,
+		"release_url":  release.URL,
+		"date_created": release.DateCreated,
+	}
+	addProjectOutputs(outputs, projects)
+	if projectResults != nil {
+		outputs["project_results"] = projectResults
+	}
+	if cfg.AdoptExisting {
+		outputs["adopted"] = adopted
+	}
+	if cfg.MinCommits > 0 {
+		outputs["commit_count"] = len(collectCommits(releaseCtx.Changes))
+		outputs["skipped_min_commits"] = false
+	}
+	if previous := p.previousReleaseVersion(cfg, releaseCtx); previous != "" {
+		outputs["compare_url"] = compareURL(cfg, release.Version, previous, projects)
+	}
+	if githubURL != "" {
+		outputs["github_release_url"] = githubURL
+	}
+	addCIOutputs(outputs, ci)
+	if len(issues) > 0 {
+		outputs["issues"] = issues
+	}
+
+	results := []string{fmt.Sprintf("Created Sentry release: %s", release.Version)}
+	if adopted {
+		results = []string{fmt.Sprintf("Adopted existing Sentry release: %s", release.Version)}
+	}
+	if refWarning != "" {
+		results = append(results, "Warning: "+refWarning)
+	}
+	if tagWarning != "" {
+		results = append(results, "Warning: "+tagWarning)
+	}
+	if failedProjects := failedProjectSlugs(projectResults); len(failedProjects) > 0 {
+		results = append(results, fmt.Sprintf("Warning: Failed to create release in projects: %s", strings.Join(failedProjects, ", ")))
+	}
+
+	// Show the repository names Sentry expects for commits.repository
+	if cfg.ListRepositories {
+		if repos, err := client.ListRepositories(ctx); err != nil {
+			results = append(results, fmt.Sprintf("Warning: Failed to list Sentry repositories: %v", err))
+		} else {
+			outputs["sentry_repositories"] = repositoryOutputs(repos)
+		}
+	}
+
+	// Let other systems react to the new release
+	if cfg.ReleaseWebhook.URL != "" && !adopted {
+		payload := releaseCreatedPayload{
+			Event:      releaseCreatedEvent,
+			Release:    release,
+			Projects:   projects,
+			ReleaseURL: releaseWebURL(cfg, release.Version),
+		}
+		if err := sendWebhook(ctx, cfg.ReleaseWebhook.URL, payload, cfg.Retry); err != nil {
+			if cfg.ReleaseWebhook.FailOnError {
+				return &plugin.ExecuteResponse{
+					Success: false,
+					Error:   fmt.Sprintf("Release webhook failed: %v", err),
+					Outputs: outputs,
+				}, nil
+			}
+			results = append(results, fmt.Sprintf("Warning: Release webhook failed: %v", err))
+		} else {
+			results = append(results, "Sent release webhook")
+		}
+	}
+
+	// Upload source maps
+	uploadedNames := make(map[string]bool)
+	if cfg.UploadSourcemaps {
+		uploaded, err := client.UploadSourcemaps(ctx, release.Version, cfg.Sourcemaps)
+		for _, f := range uploaded {
+			uploadedNames[f.Name] = true
+		}
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to upload source maps (%d uploaded): %v", len(uploaded), err),
+				Outputs: outputs,
+			}, nil
+		}
+		results = append(results, fmt.Sprintf("Uploaded %d source map files", len(uploaded)))
+		outputs["sourcemaps_uploaded"] = len(uploaded)
+	}
+
+	// Prune stale release files, keeping those just uploaded
+	if cfg.Sourcemaps.Prune {
+		pruned, err := pruneReleaseFiles(ctx, client, release.Version, cfg.Sourcemaps.PruneOlderThan, uploadedNames, cfg.Concurrency, cfg.FanOutStagger)
+		if err != nil {
+			results = append(results, fmt.Sprintf("Warning: Failed to prune release files: %v", err))
+		} else {
+			results = append(results, fmt.Sprintf("Pruned %d stale release files (%d bytes)", pruned.Files, pruned.Bytes))
+		}
+		outputs["pruned_files"] = pruned.Files
+		outputs["pruned_bytes"] = pruned.Bytes
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: strings.Join(results, "; "),
+		Outputs: outputs,
+	}, nil
+}
+
+// mergeVersionInfo merges version info entries, with later maps taking precedence.
+func mergeVersionInfo(infos ...map[string]string) map[string]string {
+	var merged map[string]string
+	for _, info := range infos {
+		for k, v := range info {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// addProjectOutputs adds the release's projects to the response outputs, as
+// the "projects" list and, for consumers expecting a scalar, as "project"
+// when there is exactly one.
+func addProjectOutputs(outputs map[string]any, projects []string) {
+	outputs["projects"] = projects
+	if len(projects) == 1 {
+		outputs["project"] = projects[0]
+	}
+}
+
+// addCIOutputs adds detected CI metadata to the response outputs.
+func addCIOutputs(outputs map[string]any, ci *CIMetadata) {
+	if ci == nil {
+		return
+	}
+	outputs["ci_provider"] = ci.Provider
+	if ci.BuildURL != "" {
+		outputs["ci_build_url"] = ci.BuildURL
+	}
+}
+
+// handlePostPublish finalizes the release and creates deploy record.
+func (p *SentryPlugin) handlePostPublish(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
+	version, err := p.formatVersion(cfg.VersionFormat, cfg.versionVars(), releaseCtx)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to format version: %v", err),
+		}, nil
+	}
+
+	var results []string
+
+	if dryRun {
+		if cfg.SetCommits {
+			results = append(results, "Would associate commits with release")
+		}
+		if cfg.CreateDeploy {
+			if cfg.Deploy.HealthcheckURL != "" {
+				results = append(results, fmt.Sprintf("Would check %s before creating deploys", cfg.Deploy.HealthcheckURL))
+			}
+			results = append(results, fmt.Sprintf("Would create deploy for environment: %s", strings.Join(cfg.Deploy.environments(), ", ")))
+			if cfg.Deploy.Atomic {
+				results = append(results, "Would roll back created deploys if any deploy fails")
+			}
+		}
+		if cfg.Finalize {
+			if cfg.SkipFinalizeForPrerelease && isPrerelease(releaseCtx.Version) {
+				results = append(results, "Would skip finalize (prerelease)")
+			} else {
+				results = append(results, "Would finalize release")
+				if cfg.FinalizeWebhook.URL != "" {
+					results = append(results, "Would send finalize webhook")
+				}
+			}
+		}
+
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: strings.Join(results, "; "),
+			Outputs: map[string]any{
+				"version": version,
+				"status":  statusOK,
+			},
+		}, nil
+	}
 
-// Validate validates the plugin configuration.
-func (p *SentryPlugin) Validate(ctx context.Context, config map[string]any) (*plugin.ValidateResponse, error) {
-	vb := helpers.NewValidationBuilder()
-	cfg := p.parseConfig(config)
+	client := cfg.newClient()
 
-	// Validate auth token
-	if cfg.AuthToken == "" {
-		vb.AddError("auth_token", "Sentry auth token is required")
-		return vb.Build(), nil
+	// Track step outcomes for the status output
+	var succeeded, failed int
+	summary := stepSummary{
+		Version:    version,
+		ReleaseURL: releaseWebURL(cfg, version),
+	}
+	outputs := map[string]any{
+		"version":     version,
+		"release_url": summary.ReleaseURL,
 	}
 
-	// Validate organization
-	if cfg.Org == "" {
-		vb.AddError("org", "Sentry organization is required")
+	// Leave releases that already shipped untouched on re-runs
+	if cfg.ProtectFinalized {
+		existing, err := client.GetRelease(ctx, version)
+		protected := err == nil && !existing.DateReleased.IsZero() && !cfg.Force
+		outputs["protected"] = protected
+		if protected {
+			outputs["status"] = statusOK
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: fmt.Sprintf("Release %s was finalized at %s; skipping commits, deploys, and finalize (protect_finalized, set force to override)", version, existing.DateReleased.Format(time.RFC3339)),
+				Outputs: outputs,
+			}, nil
+		}
 	}
 
-	// Validate projects
-	projects := cfg.getProjects()
-	if len(projects) == 0 {
-		vb.AddError("project", "At least one project is required")
+	// Associate commits and create deploys. The steps are independent, so
+	// they run concurrently when parallel_steps is set; finalize always runs
+	// after both have completed.
+	var commitStep, deployStep stepOutcome
+	if cfg.ParallelSteps {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			commitStep = p.associateCommits(ctx, client, cfg, releaseCtx, version, &summary)
+		}()
+		deployStep = p.createDeploys(ctx, client, cfg, releaseCtx, version, &summary)
+		wg.Wait()
+	} else {
+		commitStep = p.associateCommits(ctx, client, cfg, releaseCtx, version, &summary)
+		deployStep = p.createDeploys(ctx, client, cfg, releaseCtx, version, &summary)
+	}
+	for _, step := range []stepOutcome{commitStep, deployStep} {
+		results = append(results, step.results...)
+		succeeded += step.succeeded
+		failed += step.failed
+		for k, v := range step.outputs {
+			outputs[k] = v
+		}
 	}
 
-	// Validate version format template
-	if cfg.VersionFormat != "" {
-		_, err := template.New("").Parse(cfg.VersionFormat)
-		if err != nil {
-			vb.AddError("version_format", fmt.Sprintf("Invalid version format template: %v", err))
+	// Finalize release, leaving prereleases open when configured
+	if cfg.Finalize && cfg.SkipFinalizeForPrerelease && isPrerelease(releaseCtx.Version) {
+		results = append(results, "Skipped finalize (prerelease)")
+	} else if cfg.Finalize {
+		if err := client.FinalizeRelease(ctx, version); isNotFound(err) {
+			// Usually PostPublish ran without PrePublish creating the release
+			results = append(results, fmt.Sprintf("Warning: Failed to finalize release: release %s not found — was it created in PrePublish?", version))
+			failed++
+		} else if err != nil {
+			results = append(results, fmt.Sprintf("Warning: Failed to finalize release: %v", err))
+			failed++
+		} else {
+			results = append(results, "Finalized release")
+			succeeded++
+
+			// Notify downstream automation that the release is final
+			if cfg.FinalizeWebhook.URL != "" {
+				payload := finalizeWebhookPayload{
+					Version:    version,
+					Projects:   cfg.getProjects(),
+					ReleaseURL: summary.ReleaseURL,
+					DeployURL:  summary.DeployURL,
+				}
+				if err := sendWebhook(ctx, cfg.FinalizeWebhook.URL, payload, cfg.Retry); err != nil {
+					if cfg.FinalizeWebhook.FailOnError {
+						return &plugin.ExecuteResponse{
+							Success: false,
+							Error:   fmt.Sprintf("Finalize webhook failed: %v", err),
+							Outputs: outputs,
+						}, nil
+					}
+					results = append(results, fmt.Sprintf("Warning: Finalize webhook failed: %v", err))
+					failed++
+				} else {
+					results = append(results, "Sent finalize webhook")
+					succeeded++
+				}
+			}
 		}
 	}
 
-	// Test API connectivity if auth token is provided
-	if cfg.AuthToken != "" && cfg.Org != "" {
-		client := NewSentryClient(cfg.URL, cfg.AuthToken, cfg.Org)
-		if _, err := client.GetOrganization(ctx); err != nil {
-			vb.AddError("auth_token", fmt.Sprintf("Failed to authenticate with Sentry: %v", err))
+	// Write GitHub Actions job summary
+	if cfg.Summary {
+		path := envLookup(releaseCtx.Environment)("GITHUB_STEP_SUMMARY")
+		if err := writeStepSummary(path, summary); err != nil {
+			results = append(results, fmt.Sprintf("Warning: Failed to write job summary: %v", err))
 		}
 	}
 
-	return vb.Build(), nil
+	if len(results) == 0 {
+		results = append(results, "No actions taken")
+	}
+
+	outputs["status"] = stepStatus(succeeded, failed)
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: strings.Join(results, "; "),
+		Outputs: outputs,
+	}, nil
 }
 
-// parseConfig parses and applies defaults to the configuration.
-func (p *SentryPlugin) parseConfig(raw map[string]any) *Config {
-	parser := helpers.NewConfigParser(raw)
+// stepOutcome collects the results of a PostPublish step, so independent
+// steps can run concurrently and be merged in a fixed order.
+type stepOutcome struct {
+	results   []string
+	succeeded int
+	failed    int
+	outputs   map[string]any
+}
 
-	cfg := &Config{
-		AuthToken:        parser.GetString("auth_token", "SENTRY_AUTH_TOKEN", ""),
-		Org:              parser.GetString("org", "SENTRY_ORG", ""),
-		Project:          parser.GetString("project", "SENTRY_PROJECT", ""),
-		URL:              parser.GetString("url", "SENTRY_URL", "https://sentry.io"),
-		VersionFormat:    parser.GetString("version_format", "", "{{.Version}}"),
-		Environment:      parser.GetString("environment", "", "production"),
-		SetCommits:       parser.GetBool("set_commits", true),
-		CreateDeploy:     parser.GetBool("create_deploy", true),
-		UploadSourcemaps: parser.GetBool("upload_sourcemaps", false),
-		Finalize:         parser.GetBool("finalize", true),
-	}
-
-	// Parse projects array
-	if projects, ok := raw["projects"].([]any); ok {
-		for _, p := range projects {
-			if s, ok := p.(string); ok {
-				cfg.Projects = append(cfg.Projects, s)
-			}
-		}
+// output records a step output.
+func (s *stepOutcome) output(key string, value any) {
+	if s.outputs == nil {
+		s.outputs = make(map[string]any)
 	}
+	s.outputs[key] = value
+}
 
-	// Parse commits config
-	if commits, ok := raw["commits"].(map[string]any); ok {
-		commitParser := helpers.NewConfigParser(commits)
-		cfg.Commits = CommitsConfig{
-			Auto:       commitParser.GetBool("auto", true),
-			Repository: commitParser.GetString("repository", "", ""),
+// associateCommits associates the release's commits with the release, or
+// the commits since the last deploy when commits.scope is deploy.
+func (p *SentryPlugin) associateCommits(ctx context.Context, client *SentryClient, cfg *Config, releaseCtx plugin.ReleaseContext, version string, summary *stepSummary) stepOutcome {
+	var step stepOutcome
+
+	if cfg.SetCommits {
+		var err error
+		if releaseCtx, err = withGitRemote(ctx, cfg, releaseCtx); err != nil && !cfg.Commits.Auto {
+			step.results = append(step.results, fmt.Sprintf("Warning: Could not detect the commit repository from the git remote (%v); commits are associated with repository %q until commits.repository is set", err, unknownRepository))
 		}
-	} else {
-		cfg.Commits = CommitsConfig{Auto: true}
 	}
 
-	// Parse deploy config
-	if deploy, ok := raw["deploy"].(map[string]any); ok {
-		deployParser := helpers.NewConfigParser(deploy)
-		cfg.Deploy = DeployConfig{
-			Environment: deployParser.GetString("environment", "", cfg.Environment),
-			Name:        deployParser.GetString("name", "", ""),
-		}
-	} else {
-		cfg.Deploy = DeployConfig{
-			Environment: cfg.Environment,
+	var deployRef *CommitRef
+	if cfg.SetCommits && cfg.Commits.Scope == commitsScopeDeploy && cfg.Commits.From == "" && cfg.Commits.To == "" {
+		var err error
+		deployRef, err = deployCommitRef(ctx, client, cfg, releaseCtx, version)
+		if err != nil {
+			step.results = append(step.results, fmt.Sprintf("Warning: %v; associating commits since the last release", err))
 		}
 	}
-
-	// Parse sourcemaps config
-	if sourcemaps, ok := raw["sourcemaps"].(map[string]any); ok {
-		smParser := helpers.NewConfigParser(sourcemaps)
-		cfg.Sourcemaps = SourcemapsConfig{
-			Path:      smParser.GetString("path", "", "./dist"),
-			URLPrefix: smParser.GetString("url_prefix", "", "~/"),
+	if cfg.SetCommits && (cfg.Commits.From != "" || cfg.Commits.To != "") {
+		ref, err := explicitCommitRef(cfg, releaseCtx)
+		if err == nil {
+			err = client.SetCommitRefs(ctx, version, []CommitRef{*ref})
 		}
-		if include, ok := sourcemaps["include"].([]any); ok {
-			for _, i := range include {
-				if s, ok := i.(string); ok {
-					cfg.Sourcemaps.Include = append(cfg.Sourcemaps.Include, s)
-				}
+		if err != nil {
+			step.results = append(step.results, fmt.Sprintf("Warning: Failed to set commits: %v", err))
+			step.failed++
+		} else if ref.PreviousCommit != "" {
+			step.results = append(step.results, fmt.Sprintf("Associated commits %s..%s", shortSHA(ref.PreviousCommit, cfg.ShortSHALength), shortSHA(ref.Commit, cfg.ShortSHALength)))
+			step.succeeded++
+		} else {
+			step.results = append(step.results, fmt.Sprintf("Associated commits up to %s", shortSHA(ref.Commit, cfg.ShortSHALength)))
+			step.succeeded++
+		}
+	} else if deployRef != nil {
+		if err := client.SetCommitRefs(ctx, version, []CommitRef{*deployRef}); err != nil {
+			step.results = append(step.results, fmt.Sprintf("Warning: Failed to set commits: %v", err))
+			step.failed++
+		} else {
+			step.results = append(step.results, fmt.Sprintf("Associated commits since last deploy to %s (%s)", cfg.Deploy.Environment, shortSHA(deployRef.PreviousCommit, cfg.ShortSHALength)))
+			step.succeeded++
+		}
+	} else if cfg.SetCommits && cfg.Commits.Auto {
+		refs, err := autoCommitRefs(cfg, releaseCtx)
+		if err == nil {
+			err = client.SetCommitsAuto(ctx, version, refs...)
+		}
+		if err != nil {
+			step.results = append(step.results, fmt.Sprintf("Warning: Failed to set commits automatically: %v", err))
+			step.failed++
+		} else if len(refs) > 0 {
+			step.results = append(step.results, fmt.Sprintf("Associated commits automatically from %s up to %s", refs[0].Repository, shortSHA(refs[0].Commit, cfg.ShortSHALength)))
+			step.succeeded++
+		} else {
+			step.results = append(step.results, "Associated commits automatically from repository integrations")
+			step.succeeded++
+		}
+	} else if cfg.SetCommits {
+		commits, err := p.extractCommits(cfg, releaseCtx)
+		if err != nil {
+			step.results = append(step.results, fmt.Sprintf("Warning: Failed to set commits: %v", err))
+			step.failed++
+		}
+		if cfg.EnrichCommitsFromGit && len(commits) > 0 {
+			if err := enrichCommitsFromGit(ctx, commits); err != nil {
+				step.results = append(step.results, fmt.Sprintf("Warning: %v", err))
 			}
 		}
-		if exclude, ok := sourcemaps["exclude"].([]any); ok {
-			for _, e := range exclude {
-				if s, ok := e.(string); ok {
-					cfg.Sourcemaps.Exclude = append(cfg.Sourcemaps.Exclude, s)
+		if cfg.Commits.Sort == commitsSortChronological {
+			sortCommitsChronologically(commits)
+		}
+		if len(commits) > 0 {
+			unassociated, err := client.SetCommits(ctx, version, commits)
+			if err != nil {
+				step.results = append(step.results, fmt.Sprintf("Warning: Failed to set commits: %v", err))
+				step.failed++
+			} else {
+				associated := len(commits) - len(unassociated)
+				if len(unassociated) > 0 {
+					step.results = append(step.results, fmt.Sprintf("Associated %d commits (%d unassociated)", associated, len(unassociated)))
+					step.output("unassociated_commits", unassociated)
+				} else {
+					step.results = append(step.results, fmt.Sprintf("Associated %d commits", associated))
 				}
+				summary.Commits = associated
+				step.output("commits_associated", associated)
+				cfg.metrics.add(metricCommitsAssociated, int64(associated))
+				step.succeeded++
 			}
 		}
 	}
 
-	return cfg
+	return step
 }
 
-// getProjects returns all configured projects.
-func (cfg *Config) getProjects() []string {
-	projects := cfg.Projects
-	if cfg.Project != "" {
-		// Check if already in list
-		found := false
-		for _, p := range projects {
-			if p == cfg.Project {
-				found = true
-				break
+// createDeploys records a deploy for each configured environment, after the
+// optional health check.
+func (p *SentryPlugin) createDeploys(ctx context.Context, client *SentryClient, cfg *Config, releaseCtx plugin.ReleaseContext, version string, summary *stepSummary) stepOutcome {
+	var step stepOutcome
+
+	// Check the environment is serving before recording a deploy
+	createDeploy := cfg.CreateDeploy
+	if createDeploy && cfg.Deploy.HealthcheckURL != "" {
+		if err := checkHealth(ctx, cfg.Deploy.HealthcheckURL, cfg.Deploy.HealthcheckTimeout); err != nil {
+			step.results = append(step.results, fmt.Sprintf("Warning: Skipped deploy: %v", err))
+			step.output("healthcheck", "failed")
+			step.failed++
+			createDeploy = false
+		} else {
+			step.output("healthcheck", "passed")
+		}
+	}
+
+	// Create deploy
+	if createDeploy {
+		var created []*Deploy
+		var commitRange string
+		rolledBack := false
+		for _, env := range cfg.Deploy.environments() {
+			var envRange string
+			if cfg.Deploy.CommitRange {
+				var err error
+				if envRange, err = deployCommitRange(ctx, client, env, version, releaseCtx.CommitSHA); err != nil {
+					step.results = append(step.results, fmt.Sprintf("Warning: %v", err))
+				}
+			}
+			name, err := cfg.Deploy.nameFor(version, env)
+			if err != nil {
+				step.results = append(step.results, fmt.Sprintf("Warning: Failed to render deploy name for %s: %v", env, err))
+			}
+			deploy, err := client.CreateDeploy(ctx, version, DeployConfig{Environment: env, Name: name, DeployedBy: cfg.Deploy.DeployedBy})
+			if err != nil {
+				step.results = append(step.results, fmt.Sprintf("Warning: Failed to create deploy for %s: %v", env, err))
+				step.failed++
+				if cfg.Deploy.Atomic {
+					// Undo the deploys from this run so no environment is left half-promoted
+					step.results = append(step.results, rollbackDeploys(ctx, client, version, created)...)
+					step.succeeded -= len(created)
+					step.failed += len(created)
+					summary.Environment, summary.DeployURL = "", ""
+					rolledBack = true
+					break
+				}
+				continue
 			}
+			created = append(created, deploy)
+			commitRange = envRange
+			step.results = append(step.results, fmt.Sprintf("Created deploy: %s", deploy.Environment))
+			summary.Environment = deploy.Environment
+			summary.DeployURL = summary.ReleaseURL + "?environment=" + url.QueryEscape(deploy.Environment)
+			step.succeeded++
 		}
-		if !found {
-			projects = append(projects, cfg.Project)
+		if len(created) > 0 && !rolledBack {
+			step.output("deploy_id", created[len(created)-1].ID)
+			step.output("deploy_url", summary.DeployURL)
+			if cfg.Deploy.DeployedBy != "" {
+				step.output("deployed_by", cfg.Deploy.DeployedBy)
+			}
+			if commitRange != "" {
+				step.output("deploy_commit_range", commitRange)
+			}
 		}
 	}
-	return projects
+
+	return step
 }
 
-// formatVersion renders the version string using the template.
-func (p *SentryPlugin) formatVersion(format string, ctx plugin.ReleaseContext) (string, error) {
-	tmpl, err := template.New("version").Parse(format)
-	if err != nil {
-		return "", err
+// rollbackDeploys deletes the given deploys and describes the outcome.
+func rollbackDeploys(ctx context.Context, client *SentryClient, version string, deploys []*Deploy) []string {
+	var results []string
+	rolledBack := 0
+	for _, d := range deploys {
+		if err := client.DeleteDeploy(ctx, version, d.ID); err != nil {
+			results = append(results, fmt.Sprintf("Warning: Failed to roll back deploy for %s: %v", d.Environment, err))
+			continue
+		}
+		rolledBack++
 	}
-
-	data := struct {
-		Version  string
-		TagName  string
-		ShortSHA string
-	}{
-		Version:  ctx.Version,
-		TagName:  ctx.TagName,
-		ShortSHA: shortSHA(ctx.CommitSHA),
+	if rolledBack > 0 {
+		results = append(results, fmt.Sprintf("Rolled back %d deploys", rolledBack))
 	}
+	return results
+}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", err
+// stepStatus summarizes step outcomes for the "status" output: "ok" when no
+// step failed, "failed" when every attempted step failed, and "partial" when
+// some steps succeeded and others failed.
+func stepStatus(succeeded, failed int) string {
+	switch {
+	case failed == 0:
+		return statusOK
+	case succeeded == 0:
+		return statusFailed
+	default:
+		return statusPartial
 	}
-
-	return buf.String(), nil
 }
 
-// shortSHA returns the first 7 characters of a SHA.
-func shortSHA(sha string) string {
-	if len(sha) > 7 {
-		return sha[:7]
+// handleOnError handles release failure.
+func (p *SentryPlugin) handleOnError(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
+	failure := failureSummary(releaseCtx, cfg.ShortSHALength)
+	if len(cfg.OnError.Actions) == 0 {
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: failure + " (no Sentry action taken)",
+		}, nil
 	}
-	return sha
-}
 
-// handlePrePublish creates the release in Sentry before publishing.
-func (p *SentryPlugin) handlePrePublish(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
-	version, err := p.formatVersion(cfg.VersionFormat, releaseCtx)
+	version, err := p.formatVersion(cfg.VersionFormat, cfg.versionVars(), releaseCtx)
 	if err != nil {
 		return &plugin.ExecuteResponse{
 			Success: false,
@@ -270,157 +2521,338 @@ func (p *SentryPlugin) handlePrePublish(ctx context.Context, cfg *Config, releas
 		}, nil
 	}
 
-	projects := cfg.getProjects()
-
 	if dryRun {
+		message := fmt.Sprintf("%s; would run on-error actions for release %s: %s", failure, version, strings.Join(cfg.OnError.Actions, ", "))
+		if cfg.OnError.RollbackDelay > 0 && slices.Contains(cfg.OnError.Actions, onErrorDeleteRelease) {
+			message += fmt.Sprintf(" (after waiting %s)", cfg.OnError.RollbackDelay)
+		}
 		return &plugin.ExecuteResponse{
 			Success: true,
-			Message: fmt.Sprintf("Would create Sentry release '%s' for projects: %s", version, strings.Join(projects, ", ")),
-			Outputs: map[string]any{
-				"version":  version,
-				"projects": projects,
-			},
+			Message: message,
 		}, nil
 	}
 
-	client := NewSentryClient(cfg.URL, cfg.AuthToken, cfg.Org)
+	client := cfg.newClient()
 
-	// Create release
-	release, err := client.CreateRelease(ctx, version, projects)
-	if err != nil {
+	results := []string{failure}
+	var failures []string
+	for _, action := range cfg.OnError.Actions {
+		switch action {
+		case onErrorArchiveRelease:
+			if err := client.ArchiveRelease(ctx, version); isNotFound(err) {
+				results = append(results, fmt.Sprintf("Release %s not found; nothing to archive", version))
+			} else if err != nil {
+				failures = append(failures, fmt.Sprintf("Failed to archive release: %v", err))
+			} else {
+				results = append(results, fmt.Sprintf("Archived release %s", version))
+			}
+		case onErrorDeleteRelease:
+			if delay := cfg.OnError.RollbackDelay; delay > 0 {
+				if err := sleepContext(ctx, delay); err != nil {
+					failures = append(failures, fmt.Sprintf("Cancelled while waiting %s to delete release: %v", delay, err))
+					continue
+				}
+				results = append(results, fmt.Sprintf("Waited %s before deleting release", delay))
+			}
+			if err := client.DeleteRelease(ctx, version); err != nil {
+				failures = append(failures, fmt.Sprintf("Failed to delete release: %v", err))
+			} else {
+				results = append(results, fmt.Sprintf("Deleted release %s", version))
+			}
+		}
+	}
+
+	if len(failures) > 0 && cfg.OnError.FailOnError {
 		return &plugin.ExecuteResponse{
 			Success: false,
-			Error:   fmt.Sprintf("Failed to create release: %v", err),
+			Message: strings.Join(results, "; "),
+			Error:   strings.Join(failures, "; "),
 		}, nil
 	}
+	for _, failure := range failures {
+		results = append(results, "Warning: "+failure)
+	}
 
 	return &plugin.ExecuteResponse{
 		Success: true,
-		Message: fmt.Sprintf("Created Sentry release: %s", release.Version),
-		Outputs: map[string]any{
-			"version":      release.Version,
-			"release_url":  release.URL,
-			"date_created": release.DateCreated,
-		},
+		Message: strings.Join(results, "; "),
 	}, nil
 }
 
-// handlePostPublish finalizes the release and creates deploy record.
-func (p *SentryPlugin) handlePostPublish(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
-	version, err := p.formatVersion(cfg.VersionFormat, releaseCtx)
-	if err != nil {
-		return &plugin.ExecuteResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to format version: %v", err),
-		}, nil
+// failureSummary describes the failed release from what the release context
+// records about it, e.g. "Release v1.2.3 failed (commit abc1234 on main)".
+// The commit SHA is shortened to shortSHALength.
+func failureSummary(releaseCtx plugin.ReleaseContext, shortSHALength int) string {
+	name := releaseCtx.TagName
+	if name == "" {
+		name = releaseCtx.Version
+	}
+	summary := "Release failed"
+	if name != "" {
+		summary = fmt.Sprintf("Release %s failed", name)
 	}
 
-	var results []string
+	var details []string
+	if sha := releaseCtx.CommitSHA; sha != "" {
+		details = append(details, "commit "+shortSHA(sha, shortSHALength))
+	}
+	if releaseCtx.Branch != "" {
+		details = append(details, "on "+releaseCtx.Branch)
+	}
+	if len(details) > 0 {
+		summary += " (" + strings.Join(details, " ") + ")"
+	}
+	return summary
+}
 
-	if dryRun {
-		if cfg.SetCommits {
-			results = append(results, "Would associate commits with release")
-		}
-		if cfg.CreateDeploy {
-			results = append(results, fmt.Sprintf("Would create deploy for environment: %s", cfg.Deploy.Environment))
-		}
-		if cfg.Finalize {
-			results = append(results, "Would finalize release")
-		}
+// extractCommits extracts commit information from the release context.
+func (p *SentryPlugin) extractCommits(cfg *Config, releaseCtx plugin.ReleaseContext) ([]CommitSpec, error) {
+	var commits []CommitSpec
 
-		return &plugin.ExecuteResponse{
-			Success: true,
-			Message: strings.Join(results, "; "),
-			Outputs: map[string]any{
-				"version": version,
-			},
-		}, nil
+	if releaseCtx.Changes == nil {
+		return commits, nil
 	}
 
-	client := NewSentryClient(cfg.URL, cfg.AuthToken, cfg.Org)
+	repository, err := detectRepository(cfg, releaseCtx)
+	if err != nil {
+		return nil, err
+	}
 
-	// Associate commits
-	if cfg.SetCommits {
-		commits := p.extractCommits(cfg, releaseCtx)
-		if len(commits) > 0 {
-			if err := client.SetCommits(ctx, version, commits); err != nil {
-				results = append(results, fmt.Sprintf("Warning: Failed to set commits: %v", err))
-			} else {
-				results = append(results, fmt.Sprintf("Associated %d commits", len(commits)))
+	source := collectCommits(releaseCtx.Changes)
+	if cfg.Commits.Sort == commitsSortType {
+		sortCommitsByType(source)
+	}
+	for _, c := range source {
+		message := c.Description
+		if strings.TrimSpace(message) == "" {
+			switch cfg.Commits.EmptyMessagePolicy {
+			case emptyMessageSkip:
+				continue
+			case emptyMessagePlaceholder:
+				message = emptyMessagePlaceholderText
 			}
 		}
+		if cfg.Commits.IncludeTypeScope {
+			message = conventionalHeader(c, message)
+		}
+		commitRepository := repository
+		if scoped, ok := cfg.Commits.ScopeRepositories[c.Scope]; ok && c.Scope != "" {
+			commitRepository = scoped
+		}
+		authorName, authorEmail := commitAuthor(c.Author)
+		commits = append(commits, CommitSpec{
+			ID:          truncateHash(c.Hash, cfg.CommitHashLength),
+			Repository:  commitRepository,
+			Message:     message,
+			AuthorName:  authorName,
+			AuthorEmail: authorEmail,
+			Timestamp:   commitTimestamp(c.Date, timeNow()),
+		})
 	}
 
-	// Create deploy
-	if cfg.CreateDeploy {
-		deploy, err := client.CreateDeploy(ctx, version, cfg.Deploy)
-		if err != nil {
-			results = append(results, fmt.Sprintf("Warning: Failed to create deploy: %v", err))
-		} else {
-			results = append(results, fmt.Sprintf("Created deploy: %s", deploy.Environment))
-		}
+	return commits, nil
+}
+
+// deployCommitRef returns the commit range from the release last deployed to
+// the deploy environment up to the release commit.
+func deployCommitRef(ctx context.Context, client *SentryClient, cfg *Config, releaseCtx plugin.ReleaseContext, version string) (*CommitRef, error) {
+	if releaseCtx.CommitSHA == "" {
+		return nil, fmt.Errorf("no release commit to scope commits to the last deploy")
 	}
 
-	// Finalize release
-	if cfg.Finalize {
-		if err := client.FinalizeRelease(ctx, version); err != nil {
-			results = append(results, fmt.Sprintf("Warning: Failed to finalize release: %v", err))
-		} else {
-			results = append(results, "Finalized release")
-		}
+	previous, err := previousDeployRef(ctx, client, cfg.Deploy.Environment, version)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(results) == 0 {
-		results = append(results, "No actions taken")
+	repository, err := detectRepository(cfg, releaseCtx)
+	if err != nil {
+		return nil, err
 	}
 
-	return &plugin.ExecuteResponse{
-		Success: true,
-		Message: strings.Join(results, "; "),
-		Outputs: map[string]any{
-			"version": version,
-		},
+	return &CommitRef{
+		Repository:     repository,
+		Commit:         releaseCtx.CommitSHA,
+		PreviousCommit: previous,
 	}, nil
 }
 
-// handleOnError handles release failure.
-func (p *SentryPlugin) handleOnError(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
-	// For now, just log that an error occurred
-	// Could be extended to update release status or create an issue
-	return &plugin.ExecuteResponse{
-		Success: true,
-		Message: "Release failure noted (no Sentry action taken)",
+// autoCommitRefs returns the ref sent with automatic commit association:
+// the release commit in the detected repository. Without a release commit
+// or a known repository it returns none, leaving Sentry to use every
+// repository it has integrated.
+func autoCommitRefs(cfg *Config, releaseCtx plugin.ReleaseContext) ([]CommitRef, error) {
+	if releaseCtx.CommitSHA == "" {
+		return nil, nil
+	}
+	repository, err := detectRepository(cfg, releaseCtx)
+	if err != nil {
+		return nil, err
+	}
+	if repository == unknownRepository {
+		return nil, nil
+	}
+	return []CommitRef{{Repository: repository, Commit: releaseCtx.CommitSHA}}, nil
+}
+
+// explicitCommitRef returns the commit range configured with commits.from
+// and commits.to, ending at the release commit when to is not set.
+func explicitCommitRef(cfg *Config, releaseCtx plugin.ReleaseContext) (*CommitRef, error) {
+	commit := cfg.Commits.To
+	if commit == "" {
+		commit = releaseCtx.CommitSHA
+	}
+	if commit == "" {
+		return nil, fmt.Errorf("commits.from is set but there is no commits.to or release commit to end the range")
+	}
+
+	repository, err := detectRepository(cfg, releaseCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CommitRef{
+		Repository:     repository,
+		Commit:         commit,
+		PreviousCommit: cfg.Commits.From,
 	}, nil
 }
 
-// extractCommits extracts commit information from the release context.
-func (p *SentryPlugin) extractCommits(cfg *Config, releaseCtx plugin.ReleaseContext) []CommitSpec {
-	var commits []CommitSpec
+// deployCommitRange returns the commit range deployed to env by this
+// release, from the previous deploy's release ref to commit, as "from..to".
+func deployCommitRange(ctx context.Context, client *SentryClient, env, version, commit string) (string, error) {
+	if commit == "" {
+		return "", fmt.Errorf("no release commit to compute the deploy commit range for %s", env)
+	}
+	previous, err := previousDeployRef(ctx, client, env, version)
+	if err != nil {
+		return "", err
+	}
+	return previous + ".." + commit, nil
+}
 
-	if releaseCtx.Changes == nil {
-		return commits
+// previousDeployRef returns the commit ref of the release last deployed to
+// env, ignoring version itself.
+func previousDeployRef(ctx context.Context, client *SentryClient, env, version string) (string, error) {
+	last, err := client.GetLatestDeployedRelease(ctx, env, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the last deploy to %s: %w", env, err)
+	}
+	if last == nil || last.Ref == "" {
+		return "", fmt.Errorf("no previous deploy to %s with a commit ref", env)
 	}
+	return last.Ref, nil
+}
 
-	repository := cfg.Commits.Repository
-	if repository == "" {
-		// Try to detect from git remote
-		repository = "unknown"
+// conventionalHeader rebuilds a conventional commit header such as
+// "feat(api)!: description" from the commit's type, scope, and breaking
+// flag. Commits without a type keep the bare description.
+func conventionalHeader(c plugin.ConventionalCommit, description string) string {
+	if c.Type == "" {
+		return description
+	}
+	header := c.Type
+	if c.Scope != "" {
+		header += "(" + c.Scope + ")"
 	}
+	if c.Breaking {
+		header += "!"
+	}
+	return strings.TrimSpace(header + ": " + description)
+}
 
-	// Collect all commits
-	allCommits := append([]plugin.ConventionalCommit{}, releaseCtx.Changes.Features...)
-	allCommits = append(allCommits, releaseCtx.Changes.Fixes...)
-	allCommits = append(allCommits, releaseCtx.Changes.Breaking...)
-	allCommits = append(allCommits, releaseCtx.Changes.Other...)
+// commitDateLayouts are the commit date formats accepted from the release
+// context, tried in order.
+var commitDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -0700",
+	"Mon Jan 2 15:04:05 2006 -0700",
+	time.RFC1123Z,
+}
 
-	for _, c := range allCommits {
-		commits = append(commits, CommitSpec{
-			ID:         c.Hash,
-			Repository: repository,
-			Message:    c.Description,
-			Timestamp:  time.Now().UTC().Format(time.RFC3339),
-		})
+// commitTimestamp converts a commit date to the RFC 3339 timestamp Sentry
+// expects, using now when the date is missing or cannot be parsed.
+func commitTimestamp(date string, now time.Time) string {
+	date = strings.TrimSpace(date)
+	for _, layout := range commitDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
 	}
+	return now.UTC().Format(time.RFC3339)
+}
+
+// commitAuthor splits a commit author such as "Jane Doe <jane@example.com>"
+// into name and email. A bare address is returned as the email and any
+// other value as the name.
+func commitAuthor(author string) (name, email string) {
+	author = strings.TrimSpace(author)
+	if author == "" {
+		return "", ""
+	}
+	if addr, err := mail.ParseAddress(author); err == nil {
+		return addr.Name, addr.Address
+	}
+	return author, ""
+}
+
+// sortCommitsByType groups commits by their own type: features, fixes,
+// breaking changes, then everything else, keeping the release context's
+// order within a group. A breaking feature listed under features is moved
+// to the breaking changes.
+func sortCommitsByType(commits []plugin.ConventionalCommit) {
+	rank := func(c plugin.ConventionalCommit) int {
+		switch {
+		case c.Breaking:
+			return 2
+		case c.Type == "feat":
+			return 0
+		case c.Type == "fix":
+			return 1
+		}
+		return 3
+	}
+	slices.SortStableFunc(commits, func(a, b plugin.ConventionalCommit) int {
+		return rank(a) - rank(b)
+	})
+}
+
+// sortCommitsChronologically orders commits oldest first by timestamp.
+// Commits whose timestamp cannot be parsed keep their relative order after
+// the dated ones.
+func sortCommitsChronologically(commits []CommitSpec) {
+	slices.SortStableFunc(commits, func(a, b CommitSpec) int {
+		ta, aErr := time.Parse(time.RFC3339, a.Timestamp)
+		tb, bErr := time.Parse(time.RFC3339, b.Timestamp)
+		switch {
+		case aErr != nil && bErr != nil:
+			return 0
+		case aErr != nil:
+			return 1
+		case bErr != nil:
+			return -1
+		}
+		return ta.Compare(tb)
+	})
+}
 
-	return commits
+// truncateHash shortens a commit hash to length characters.
+// A non-positive length keeps the full hash.
+func truncateHash(hash string, length int) string {
+	if length > 0 && len(hash) > length {
+		return hash[:length]
+	}
+	return hash
+}
+
+// collectCommits flattens the categorized changes into a single list.
+func collectCommits(changes *plugin.CategorizedChanges) []plugin.ConventionalCommit {
+	if changes == nil {
+		return nil
+	}
+	allCommits := append([]plugin.ConventionalCommit{}, changes.Features...)
+	allCommits = append(allCommits, changes.Fixes...)
+	allCommits = append(allCommits, changes.Breaking...)
+	allCommits = append(allCommits, changes.Other...)
+	return allCommits
 }
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+const (
+	// allProjectsSelector selects every project in the organization.
+	allProjectsSelector = "*"
+	// teamSelectorPrefix selects every project belonging to a team.
+	teamSelectorPrefix = "team:"
+)
+
+// teamFromSelector returns the team slug for a "team:<slug>" selector.
+func teamFromSelector(project string) (string, bool) {
+	if !strings.HasPrefix(project, teamSelectorPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(project, teamSelectorPrefix), true
+}
+
+// hasProjectSelectors reports whether any entry must be resolved via the API.
+func hasProjectSelectors(projects []string) bool {
+	for _, p := range projects {
+		if _, ok := teamFromSelector(p); ok || p == allProjectsSelector {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveProjects expands "*" and "team:<slug>" selectors into project slugs.
+// Plain slugs are kept as-is and duplicates are removed.
+func resolveProjects(ctx context.Context, client *SentryClient, projects []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var resolved []string
+
+	add := func(slug string) {
+		if !seen[slug] {
+			seen[slug] = true
+			resolved = append(resolved, slug)
+		}
+	}
+
+	for _, p := range projects {
+		switch team, isTeam := teamFromSelector(p); {
+		case p == allProjectsSelector:
+			list, err := client.ListProjects(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list projects: %w", err)
+			}
+			for _, proj := range list {
+				add(proj.Slug)
+			}
+		case isTeam:
+			list, err := client.ListTeamProjects(ctx, team)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list projects for team %s: %w", team, err)
+			}
+			if len(list) == 0 {
+				return nil, fmt.Errorf("team %s has no projects", team)
+			}
+			for _, proj := range list {
+				add(proj.Slug)
+			}
+		default:
+			add(p)
+		}
+	}
+
+	return resolved, nil
+}
+
+// Per-project outcomes reported in the "project_results" output.
+const (
+	projectResultOK     = "ok"
+	projectResultFailed = "failed"
+)
+
+// requiredProjects returns how many of total projects must succeed for the
+// threshold spec, which is either a count ("2") or a percentage ("50%").
+// An empty spec requires every project.
+func requiredProjects(spec string, total int) (int, error) {
+	if spec == "" {
+		return total, nil
+	}
+
+	if pct, ok := strings.CutSuffix(spec, "%"); ok {
+		p, err := strconv.ParseFloat(pct, 64)
+		if err != nil || p < 0 || p > 100 {
+			return 0, fmt.Errorf("invalid percentage %q: must be between 0%% and 100%%", spec)
+		}
+		return int(math.Ceil(p / 100 * float64(total))), nil
+	}
+
+	n, err := strconv.Atoi(spec)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid project count %q: must be a non-negative integer or a percentage", spec)
+	}
+	if n > total {
+		n = total
+	}
+	return n, nil
+}
+
+// createProjectReleases creates the release separately in each project so a
+// failure in one project does not fail the others. It returns the release
+// from the first successful project, the per-project outcomes, and the
+// number of projects that succeeded.
+func createProjectReleases(ctx context.Context, client *SentryClient, version string, projects []string, opts ReleaseOptions, concurrency ConcurrencyConfig, stagger time.Duration) (*Release, map[string]string, int) {
+	releases := make([]*Release, len(projects))
+	errs, spread := fanOut(ctx, len(projects), concurrency, stagger, func(ctx context.Context, i int) error {
+		release, err := client.CreateRelease(ctx, version, []string{projects[i]}, opts)
+		if err != nil {
+			return err
+		}
+		// CreateRelease falls back to an existing release, which may belong
+		// only to other projects
+		if len(release.Projects) > 0 && !releaseHasProject(release, projects[i]) {
+			return fmt.Errorf("release %s is not associated with project %s", version, projects[i])
+		}
+		releases[i] = release
+		return nil
+	})
+	client.debug.logFanOut("create releases", len(projects), spread)
+
+	var release *Release
+	results := make(map[string]string, len(projects))
+	succeeded := 0
+	for i, project := range projects {
+		if errs[i] != nil {
+			results[project] = projectResultFailed
+			continue
+		}
+		results[project] = projectResultOK
+		succeeded++
+		if release == nil {
+			release = releases[i]
+		}
+	}
+	return release, results, succeeded
+}
+
+// failedProjectSlugs returns the sorted slugs of projects that failed.
+func failedProjectSlugs(results map[string]string) []string {
+	var failed []string
+	for project, result := range results {
+		if result == projectResultFailed {
+			failed = append(failed, project)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// releaseHasProject reports whether the release belongs to the project.
+func releaseHasProject(release *Release, slug string) bool {
+	for _, p := range release.Projects {
+		if p.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// versionGroup is a set of projects published as one release, named by
+// Format.
+type versionGroup struct {
+	Format   string
+	Projects []string
+}
+
+// versionCollisionError lists pairs of projects that would publish the same
+// release although at least one of them sets its own version_format, which
+// would make their releases overwrite each other.
+type versionCollisionError struct {
+	Collisions []string
+}
+
+func (e *versionCollisionError) Error() string {
+	return "projects would overwrite each other's release: " + strings.Join(e.Collisions, "; ")
+}
+
+// versionGroups splits the configured projects into the releases they are
+// published as when projects set their own version_format. Each project's
+// format is rendered with the project as {{.Project}}, and projects on the
+// shared version_format whose versions match share a release. A project
+// whose own format renders a version another project also renders is a
+// collision, reported as a *versionCollisionError. Selectors such as
+// "team:web" use the shared version_format. Groups are ordered by their
+// first project.
+func (p *SentryPlugin) versionGroups(cfg *Config, releaseCtx plugin.ReleaseContext) ([]versionGroup, error) {
+	var groups []versionGroup
+	var collisions []string
+	index := make(map[string]int)
+	for _, project := range cfg.getProjects() {
+		format, own := cfg.ProjectVersionFormats[project]
+		if !own {
+			format = cfg.VersionFormat
+		}
+		vars := cfg.versionVars()
+		vars.Project = project
+		if hasProjectSelectors([]string{project}) {
+			vars.Project = ""
+		}
+		version, err := p.formatVersion(format, vars, releaseCtx)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", project, err)
+		}
+		if i, ok := index[version]; ok {
+			for _, other := range groups[i].Projects {
+				if _, otherOwn := cfg.ProjectVersionFormats[other]; own || otherOwn {
+					collisions = append(collisions, fmt.Sprintf("%s and %s both render %q", other, project, version))
+				}
+			}
+			groups[i].Projects = append(groups[i].Projects, project)
+			continue
+		}
+		index[version] = len(groups)
+		groups = append(groups, versionGroup{Format: format, Projects: []string{project}})
+	}
+	if len(collisions) > 0 {
+		return nil, &versionCollisionError{Collisions: collisions}
+	}
+	return groups, nil
+}
+
+// runVersionGroups runs the hook once per release in a monorepo whose
+// projects have their own version formats, and combines the results. The
+// combined outputs list each project's version under "versions" and each
+// run's outputs under "releases".
+func (p *SentryPlugin) runVersionGroups(ctx context.Context, cfg *Config, req plugin.ExecuteRequest, groups []versionGroup) (*plugin.ExecuteResponse, error) {
+	combined := &plugin.ExecuteResponse{Success: true}
+	var messages, errs, projects []string
+	versions := make(map[string]string)
+	var releases []map[string]any
+	for _, group := range groups {
+		groupCfg := *cfg
+		groupCfg.VersionFormat = group.Format
+		groupCfg.Project = ""
+		groupCfg.Projects = group.Projects
+		groupCfg.ProjectVersionFormats = nil
+
+		resp, err := p.runHook(ctx, &groupCfg, req)
+		if err != nil {
+			return nil, err
+		}
+		label := strings.Join(group.Projects, ", ")
+		if resp.Message != "" {
+			messages = append(messages, fmt.Sprintf("[%s] %s", label, resp.Message))
+		}
+		if !resp.Success {
+			combined.Success = false
+			errs = append(errs, fmt.Sprintf("[%s] %s", label, resp.Error))
+		}
+		projects = append(projects, group.Projects...)
+		if version, ok := resp.Outputs["version"].(string); ok {
+			for _, project := range group.Projects {
+				versions[project] = version
+			}
+		}
+		if resp.Outputs != nil {
+			releases = append(releases, resp.Outputs)
+		}
+	}
+
+	combined.Message = strings.Join(messages, "; ")
+	combined.Error = strings.Join(errs, "; ")
+	combined.Outputs = map[string]any{
+		"versions": versions,
+		"releases": releases,
+	}
+	addProjectOutputs(combined.Outputs, projects)
+	return combined, nil
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestResolveProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/0/organizations/my-org/projects/":
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"slug": "frontend"}, {"slug": "backend"}, {"slug": "api"}})
+		case "/api/0/teams/my-org/web/projects/":
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"slug": "frontend"}, {"slug": "admin"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"detail": "The requested resource does not exist"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	tests := []struct {
+		name     string
+		projects []string
+		expected []string
+		wantErr  bool
+	}{
+		{"plain slugs", []string{"frontend", "backend"}, []string{"frontend", "backend"}, false},
+		{"all projects", []string{"*"}, []string{"frontend", "backend", "api"}, false},
+		{"team projects", []string{"team:web"}, []string{"frontend", "admin"}, false},
+		{"team plus slug deduplicated", []string{"frontend", "team:web"}, []string{"frontend", "admin"}, false},
+		{"unknown team", []string{"team:missing"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveProjects(context.Background(), client, tt.projects)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveProjects() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("resolveProjects() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseConfigProjectSelector(t *testing.T) {
+	p := &SentryPlugin{}
+	cfg := p.parseConfig(map[string]any{"projects": "team:web"})
+
+	if len(cfg.Projects) != 1 || cfg.Projects[0] != "team:web" {
+		t.Errorf("expected projects [team:web], got %v", cfg.Projects)
+	}
+	if !hasProjectSelectors(cfg.getProjects()) {
+		t.Error("expected team selector to be detected")
+	}
+}
+
+func TestRequiredProjects(t *testing.T) {
+	tests := []struct {
+		spec     string
+		total    int
+		expected int
+		wantErr  bool
+	}{
+		{"", 3, 3, false},
+		{"2", 3, 2, false},
+		{"5", 3, 3, false},
+		{"50%", 3, 2, false},
+		{"100%", 4, 4, false},
+		{"0%", 4, 0, false},
+		{"150%", 3, 0, true},
+		{"-1", 3, 0, true},
+		{"most", 3, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := requiredProjects(tt.spec, tt.total)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("requiredProjects(%q, %d) error = %v, wantErr %v", tt.spec, tt.total, err, tt.wantErr)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("requiredProjects(%q, %d) = %d, want %d", tt.spec, tt.total, got, tt.expected)
+		}
+	}
+}
+
+func TestExecutePrePublishMinSuccessfulProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body CreateReleaseRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Projects) == 1 && body.Projects[0] == "broken" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"detail": "Invalid project"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"version": body.Version})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		threshold   any
+		wantSuccess bool
+	}{
+		{2, true},
+		{"100%", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.threshold), func(t *testing.T) {
+			p := &SentryPlugin{}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPrePublish,
+				Config: map[string]any{
+					"auth_token":              "test-token",
+					"org":                     "my-org",
+					"projects":                []any{"frontend", "backend", "broken"},
+					"url":                     server.URL,
+					"ci_metadata":             false,
+					"fan_out_stagger":         0,
+					"min_successful_projects": tt.threshold,
+				},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if resp.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error: %s)", resp.Success, tt.wantSuccess, resp.Error)
+			}
+			results, _ := resp.Outputs["project_results"].(map[string]string)
+			if results["frontend"] != projectResultOK || results["broken"] != projectResultFailed {
+				t.Errorf("unexpected project results: %v", resp.Outputs["project_results"])
+			}
+		})
+	}
+}
+
+func TestValidateReportsCollidingProjectPairs(t *testing.T) {
+	p := &SentryPlugin{}
+	resp, err := p.Validate(context.Background(), map[string]any{
+		"auth_token": "test-token",
+		"org":        "my-org",
+		"projects": []any{
+			map[string]any{"slug": "api", "version_format": "backend@{{.Version}}"},
+			map[string]any{"slug": "backend", "version_format": "backend@{{.Version}}"},
+			map[string]any{"slug": "worker", "version_format": "backend@{{.Version}}"},
+			map[string]any{"slug": "web", "version_format": "{{.Project}}@{{.Version}}"},
+			map[string]any{"slug": "admin", "version_format": "{{.Project}}@{{.Version}}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	var messages []string
+	for _, e := range resp.Errors {
+		if e.Field == "projects" {
+			messages = append(messages, e.Message)
+		}
+	}
+	// Every pair is reported, rendered with the sample release context;
+	// formats using {{.Project}} give each project its own name
+	expected := []string{
+		`Projects would overwrite each other's release: api and backend both render "backend@1.0.0-rc.1"`,
+		`Projects would overwrite each other's release: api and worker both render "backend@1.0.0-rc.1"`,
+		`Projects would overwrite each other's release: backend and worker both render "backend@1.0.0-rc.1"`,
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("projects errors = %v, want %v", messages, expected)
+	}
+}
+
+func TestVersionGroupsCollisions(t *testing.T) {
+	p := &SentryPlugin{}
+	cfg := &Config{
+		VersionFormat: "{{.Version}}",
+		Projects:      []string{"frontend", "backend", "api", "docs", "site", "worker"},
+		ProjectVersionFormats: map[string]string{
+			"frontend": "frontend@{{.Version}}",
+			"backend":  "backend@{{.Version}}",
+			"api":      "backend@{{.Version}}",
+			"worker":   "{{.Version}}",
+		},
+	}
+
+	_, err := p.versionGroups(cfg, plugin.ReleaseContext{Version: "1.2.3"})
+	var collisions *versionCollisionError
+	if !errors.As(err, &collisions) {
+		t.Fatalf("expected a versionCollisionError, got %v", err)
+	}
+	// docs and site share the shared format's release without colliding
+	expected := []string{
+		`backend and api both render "backend@1.2.3"`,
+		`docs and worker both render "1.2.3"`,
+		`site and worker both render "1.2.3"`,
+	}
+	if !reflect.DeepEqual(collisions.Collisions, expected) {
+		t.Errorf("collisions = %v, want %v", collisions.Collisions, expected)
+	}
+}
+
+func TestProjectVersionFormatsValidateMatchesExecute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req CreateReleaseRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]any{"version": req.Version})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		projects []any
+		wantOK   bool
+	}{
+		{
+			name: "shared format projects share a release",
+			projects: []any{
+				map[string]any{"slug": "frontend", "version_format": "frontend@{{.Version}}"},
+				"docs",
+				"site",
+			},
+			wantOK: true,
+		},
+		{
+			name: "own formats collide",
+			projects: []any{
+				map[string]any{"slug": "api", "version_format": "backend@{{.Version}}"},
+				map[string]any{"slug": "backend", "version_format": "backend@{{.Version}}"},
+			},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]any{
+				"auth_token":  "test-token",
+				"org":         "my-org",
+				"ci_metadata": false,
+				"projects":    tt.projects,
+			}
+
+			p := &SentryPlugin{}
+			vresp, err := p.Validate(context.Background(), config)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			collision := false
+			for _, e := range vresp.Errors {
+				if e.Field == "projects" && strings.Contains(e.Message, "overwrite each other's release") {
+					collision = true
+				}
+			}
+
+			config["url"] = server.URL
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook:    plugin.HookPrePublish,
+				Config:  config,
+				Context: plugin.ReleaseContext{Version: "1.2.3"},
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if collision == tt.wantOK || resp.Success != tt.wantOK {
+				t.Errorf("want ok = %v, got validation collision = %v and execute success = %v (%s)", tt.wantOK, collision, resp.Success, resp.Error)
+			}
+		})
+	}
+}
+
+func TestParseConfigProjectVersionFormats(t *testing.T) {
+	p := &SentryPlugin{}
+	cfg := p.parseConfig(map[string]any{
+		"projects": []any{
+			"shared",
+			map[string]any{"slug": "frontend", "version_format": "frontend@{{.Version}}"},
+			map[string]any{"slug": "backend"},
+			map[string]any{"version_format": "orphan@{{.Version}}"},
+		},
+	})
+
+	if !reflect.DeepEqual(cfg.Projects, []string{"shared", "frontend", "backend"}) {
+		t.Errorf("unexpected projects: %v", cfg.Projects)
+	}
+	if !reflect.DeepEqual(cfg.ProjectVersionFormats, map[string]string{"frontend": "frontend@{{.Version}}"}) {
+		t.Errorf("unexpected project version formats: %v", cfg.ProjectVersionFormats)
+	}
+}
+
+func TestParseConfigReleasePrefixProjectVersionFormats(t *testing.T) {
+	p := &SentryPlugin{}
+	cfg := p.parseConfig(map[string]any{
+		"release_prefix": "web@",
+		"projects": []any{
+			"shared",
+			map[string]any{"slug": "frontend", "version_format": "{{.Version}}-fe"},
+		},
+	})
+
+	if cfg.VersionFormat != "web@{{.Version}}" {
+		t.Errorf("VersionFormat = %q, want %q", cfg.VersionFormat, "web@{{.Version}}")
+	}
+	if !reflect.DeepEqual(cfg.ProjectVersionFormats, map[string]string{"frontend": "web@{{.Version}}-fe"}) {
+		t.Errorf("unexpected project version formats: %v", cfg.ProjectVersionFormats)
+	}
+}
+
+func TestExecutePrePublishProjectVersionFormats(t *testing.T) {
+	created := make(map[string][]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req CreateReleaseRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		created[req.Version] = req.Projects
+		_ = json.NewEncoder(w).Encode(map[string]any{"version": req.Version})
+	}))
+	defer server.Close()
+
+	p := &SentryPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPrePublish,
+		Config: map[string]any{
+			"auth_token":  "test-token",
+			"org":         "my-org",
+			"url":         server.URL,
+			"ci_metadata": false,
+			"projects": []any{
+				map[string]any{"slug": "frontend", "version_format": "frontend@{{.Version}}"},
+				map[string]any{"slug": "backend", "version_format": "backend@{{.Version}}"},
+				"docs",
+				"site",
+			},
+		},
+		Context: plugin.ReleaseContext{Version: "1.2.3"},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got error: %s", resp.Error)
+	}
+
+	expected := map[string][]string{
+		"frontend@1.2.3": {"frontend"},
+		"backend@1.2.3":  {"backend"},
+		"1.2.3":          {"docs", "site"},
+	}
+	if !reflect.DeepEqual(created, expected) {
+		t.Errorf("created releases = %v, want %v", created, expected)
+	}
+	versions, _ := resp.Outputs["versions"].(map[string]string)
+	if versions["frontend"] != "frontend@1.2.3" || versions["site"] != "1.2.3" {
+		t.Errorf("unexpected versions output: %v", resp.Outputs["versions"])
+	}
+}
+
+func TestValidateProjectVersionFormats(t *testing.T) {
+	p := &SentryPlugin{}
+	resp, err := p.Validate(context.Background(), map[string]any{
+		"auth_token": "test-token",
+		"org":        "my-org",
+		"projects": []any{
+			map[string]any{"slug": "api", "version_format": "backend@{{.Version}}"},
+			map[string]any{"slug": "backend", "version_format": "backend@{{.Version}}"},
+			map[string]any{"slug": "broken", "version_format": "{{.Version"},
+			"web",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	var messages []string
+	for _, e := range resp.Errors {
+		if e.Field == "projects" {
+			messages = append(messages, e.Message)
+		}
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected a template error and a collision, got %v", messages)
+	}
+	if !strings.Contains(messages[0], "project broken") || !strings.Contains(messages[1], `api and backend both render "backend@1.0.0-rc.1"`) {
+		t.Errorf("unexpected messages: %v", messages)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	limiter := newRateLimiter(100)
+
+	start := time.Now()
+	for range 5 {
+		if err := limiter.wait(context.Background()); err != nil {
+			t.Fatalf("wait() error = %v", err)
+		}
+	}
+	// The first request goes immediately; the other four wait 10ms each
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected requests to be spaced at 10ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	if limiter := newRateLimiter(0); limiter != nil {
+		t.Fatalf("expected no limiter for 0, got %+v", limiter)
+	}
+	var limiter *rateLimiter
+	if err := limiter.wait(context.Background()); err != nil {
+		t.Errorf("nil limiter wait() error = %v", err)
+	}
+}
+
+func TestRateLimiterContextCancelled(t *testing.T) {
+	limiter := newRateLimiter(0.1)
+	_ = limiter.wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected wait to stop at the deadline, got %v", err)
+	}
+}
+
+func TestRateLimiterCancelledWaitKeepsSlot(t *testing.T) {
+	limiter := newRateLimiter(20)
+	_ = limiter.wait(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled wait, got %v", err)
+	}
+
+	// The next request takes the 50ms slot the cancelled wait gave up
+	// rather than queueing behind it
+	start := time.Now()
+	if err := limiter.wait(context.Background()); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 80*time.Millisecond {
+		t.Errorf("expected the cancelled wait to leave its slot, waited %v", elapsed)
+	}
+}
+
+func TestSentryClientRateLimitWaitExcludedFromTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+		limiter:    newRateLimiter(10),
+		timeouts:   map[string]time.Duration{timeoutMetadata: 50 * time.Millisecond},
+	}
+
+	// The second request waits 100ms for the rate limit, longer than its
+	// 50ms timeout
+	for range 2 {
+		if _, err := client.GetRelease(context.Background(), "1.0.0"); err != nil {
+			t.Fatalf("expected the rate limit wait not to count against the timeout, got %v", err)
+		}
+	}
+}
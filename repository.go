@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// unknownRepository is used when no repository can be determined.
+const unknownRepository = "unknown"
+
+// hostedRepositoryHosts are the hosts whose repositories Sentry's
+// integrations name "owner/repo", without the host.
+var hostedRepositoryHosts = []string{"github.com/", "gitlab.com/"}
+
+// RepositoryTransform rewrites a detected repository into the name Sentry's
+// repository integration expects.
+type RepositoryTransform struct {
+	// HostMap replaces a leading host, e.g. {"git.corp.example.com": "github.com"}.
+	HostMap map[string]string `json:"host_map,omitempty"`
+	// StripPrefix removes the first matching prefix, e.g. "github.com/".
+	StripPrefix []string `json:"strip_prefix,omitempty"`
+}
+
+// apply rewrites the repository name.
+func (t RepositoryTransform) apply(repo string) string {
+	for from, to := range t.HostMap {
+		if rest, ok := strings.CutPrefix(repo, from+"/"); ok {
+			repo = to + "/" + rest
+			break
+		}
+	}
+	for _, prefix := range t.StripPrefix {
+		if rest, ok := strings.CutPrefix(repo, prefix); ok {
+			repo = rest
+			break
+		}
+	}
+	return repo
+}
+
+// detectRepository returns the repository used for commit association.
+// An explicitly configured repository is rendered as a template and
+// otherwise used as-is; without one, the repository is derived from the
+// release context and transformed. GitHub and GitLab remote URLs are
+// reduced to "owner/repo" before the transform, so a host_map rewrite is
+// kept.
+func detectRepository(cfg *Config, releaseCtx plugin.ReleaseContext) (string, error) {
+	if cfg.Commits.Repository != "" {
+		return renderRepository(cfg.Commits.Repository, cfg.ShortSHALength, releaseCtx)
+	}
+
+	var repo string
+	switch {
+	case releaseCtx.RepositoryURL != "":
+		repo = normalizeRepositoryURL(releaseCtx.RepositoryURL)
+		for _, host := range hostedRepositoryHosts {
+			if rest, ok := strings.CutPrefix(repo, host); ok {
+				repo = rest
+				break
+			}
+		}
+	case releaseCtx.RepositoryOwner != "" && releaseCtx.RepositoryName != "":
+		repo = releaseCtx.RepositoryOwner + "/" + releaseCtx.RepositoryName
+	default:
+		return unknownRepository, nil
+	}
+
+	return cfg.Commits.RepositoryTransform.apply(repo), nil
+}
+
+// withGitRemote fills in the release context's repository URL from the
+// git origin remote when neither commits.repository nor the release context
+// names the repository.
+func withGitRemote(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext) (plugin.ReleaseContext, error) {
+	if cfg.Commits.Repository != "" || releaseCtx.RepositoryURL != "" || (releaseCtx.RepositoryOwner != "" && releaseCtx.RepositoryName != "") {
+		return releaseCtx, nil
+	}
+	remote, err := originURL(ctx)
+	if err != nil {
+		return releaseCtx, err
+	}
+	releaseCtx.RepositoryURL = remote
+	return releaseCtx, nil
+}
+
+// renderRepository renders a commits.repository template, such as
+// "{{.Env.GITHUB_REPOSITORY_OWNER}}/api", with the release context.
+func renderRepository(text string, shaLength int, releaseCtx plugin.ReleaseContext) (string, error) {
+	repo, err := renderContextTemplate("repository", text, shaLength, releaseCtx)
+	if err != nil {
+		return "", fmt.Errorf("repository template: %w", err)
+	}
+	return repo, nil
+}
+
+// normalizeRepositoryURL converts a remote URL into "host/owner/repo" form,
+// e.g. "https://github.com/org/repo.git" and "git@github.com:org/repo.git"
+// both become "github.com/org/repo".
+func normalizeRepositoryURL(raw string) string {
+	repo := strings.TrimSpace(raw)
+	if i := strings.Index(repo, "://"); i >= 0 {
+		repo = repo[i+3:]
+		if at := strings.Index(repo, "@"); at >= 0 && at < strings.Index(repo+"/", "/") {
+			repo = repo[at+1:]
+		}
+	} else if at := strings.Index(repo, "@"); at >= 0 {
+		// scp-like syntax: git@host:owner/repo
+		repo = strings.Replace(repo[at+1:], ":", "/", 1)
+	}
+	repo = strings.TrimSuffix(repo, "/")
+	return strings.TrimSuffix(repo, ".git")
+}
+
+// githubReleaseURL returns the GitHub release page for the release tag. The
+// repository comes from GitHub Actions' GITHUB_REPOSITORY, falling back to
+// a github.com repository URL in the release context; it returns "" when
+// there is no tag or the repository is not hosted on GitHub.
+func githubReleaseURL(getenv func(string) string, releaseCtx plugin.ReleaseContext) string {
+	if releaseCtx.TagName == "" {
+		return ""
+	}
+
+	server, repo := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY")
+	if repo == "" {
+		rest, ok := strings.CutPrefix(normalizeRepositoryURL(releaseCtx.RepositoryURL), "github.com/")
+		if !ok {
+			return ""
+		}
+		server, repo = "", rest
+	}
+	if server == "" {
+		server = "https://github.com"
+	}
+
+	return fmt.Sprintf("%s/%s/releases/tag/%s", strings.TrimSuffix(server, "/"), repo, url.PathEscape(releaseCtx.TagName))
+}
+
+// unregisteredRepositoryWarnings warns about configured commit repositories
+// that are not registered in Sentry, since commit association silently does
+// nothing for them. Near matches, such as a different case or a host
+// prefix, are suggested.
+func unregisteredRepositoryWarnings(ctx context.Context, client *SentryClient, cfg *Config) []plugin.ValidationError {
+	var configured []string
+	if cfg.Commits.Repository != "" {
+		configured = append(configured, cfg.Commits.Repository)
+	}
+	for _, repo := range cfg.Commits.ScopeRepositories {
+		configured = append(configured, repo)
+	}
+	if len(configured) == 0 {
+		return nil
+	}
+
+	repos, err := client.ListRepositories(ctx)
+	if err != nil {
+		return []plugin.ValidationError{{
+			Field:   "commits.repository",
+			Message: fmt.Sprintf("Could not list Sentry repositories: %v", err),
+			Code:    validationWarningCode,
+		}}
+	}
+
+	var warnings []plugin.ValidationError
+	seen := make(map[string]bool)
+	for _, name := range configured {
+		if seen[name] || strings.Contains(name, "{{") {
+			continue
+		}
+		seen[name] = true
+		registered, suggestion := matchRepository(name, repos)
+		if registered {
+			continue
+		}
+		message := fmt.Sprintf("Repository %q is not registered in Sentry, so commits associated with it are ignored", name)
+		if suggestion != "" {
+			message += fmt.Sprintf("; did you mean %q?", suggestion)
+		}
+		warnings = append(warnings, plugin.ValidationError{
+			Field:   "commits.repository",
+			Message: message,
+			Code:    validationWarningCode,
+		})
+	}
+	return warnings
+}
+
+// matchRepository reports whether name is registered exactly and otherwise
+// returns the registered repository it most likely refers to, matching
+// case-insensitively with any host prefix and ".git" suffix removed, then by
+// the final path segment alone.
+func matchRepository(name string, repos []Repository) (bool, string) {
+	key := repositoryMatchKey(name)
+	var byBase string
+	for _, r := range repos {
+		if r.Name == name {
+			return true, ""
+		}
+		other := repositoryMatchKey(r.Name)
+		if other == key {
+			return false, r.Name
+		}
+		if byBase == "" && path.Base(other) == path.Base(key) {
+			byBase = r.Name
+		}
+	}
+	return false, byBase
+}
+
+// repositoryMatchKey normalizes a repository name for fuzzy matching.
+func repositoryMatchKey(name string) string {
+	key := strings.ToLower(normalizeRepositoryURL(name))
+	if host, rest, ok := strings.Cut(key, "/"); ok && strings.Contains(host, ".") {
+		key = rest
+	}
+	return key
+}
+
+// repositoryOutputs describes registered repositories for the
+// sentry_repositories output.
+func repositoryOutputs(repos []Repository) []map[string]string {
+	out := make([]map[string]string, 0, len(repos))
+	for _, r := range repos {
+		out = append(out, map[string]string{
+			"name":          r.Name,
+			"provider":      r.Provider.ID,
+			"external_slug": r.ExternalSlug,
+		})
+	}
+	return out
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+// defaultMaxRetries is how many times a failed request is retried before
+// the failure is returned to the caller.
+const defaultMaxRetries = 3
+
+// retryBaseDelay is the backoff before the first retry; it doubles with
+// each further attempt, plus up to half again as jitter.
+var retryBaseDelay = 500 * time.Millisecond
+
+// defaultRetryStatusCodes are the responses retried when retry.status_codes
+// is not configured.
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryConfig controls which failed requests are retried.
+type RetryConfig struct {
+	// StatusCodes replaces the default set of retryable status codes. An
+	// empty, non-nil list disables retries on error responses.
+	StatusCodes []int `json:"status_codes,omitempty"`
+	// MaxRetries replaces the default number of retries; zero disables
+	// retries.
+	MaxRetries *int `json:"max_retries,omitempty"`
+	// NonIdempotent also retries POST requests, such as creating a release
+	// or deploy, after 5xx responses and timeouts, when Sentry may already
+	// have applied them.
+	NonIdempotent bool `json:"non_idempotent,omitempty"`
+}
+
+// maxAttempts returns how many times a request is sent in total.
+func (r RetryConfig) maxAttempts() int {
+	if r.MaxRetries == nil {
+		return defaultMaxRetries + 1
+	}
+	return *r.MaxRetries + 1
+}
+
+// retriesRequest reports whether a request with the given method that
+// failed with err may be retried. Idempotent methods always may. Others
+// may when Sentry cannot have applied them: after a 429, or when the
+// connection failed before the request was sent. NonIdempotent also
+// retries them after 5xx responses and timeouts, when Sentry may have.
+func (r RetryConfig) retriesRequest(method string, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if dialError(err) {
+		return true
+	}
+	return r.NonIdempotent
+}
+
+// dialError reports whether err is a failure to connect, before any of
+// the request was sent.
+func dialError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryable reports whether a response with the given status is retried.
+func (r RetryConfig) retryable(status int) bool {
+	codes := r.StatusCodes
+	if codes == nil {
+		codes = defaultRetryStatusCodes
+	}
+	for _, code := range codes {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableError reports whether a failed request is retried: error
+// responses with a retryable status, and transient connection failures,
+// which are failures to connect, timeouts, and connections closed before
+// a response arrived. Certificate and malformed URL errors are permanent.
+func (r RetryConfig) retryableError(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return r.retryable(statusErr.StatusCode)
+	}
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthority) || errors.As(err, &hostnameErr) || errors.As(err, &invalidCert) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryDelay returns the backoff before retrying after the given attempt.
+func retryDelay(attempt int) time.Duration {
+	d := retryBaseDelay << (attempt - 1)
+	return d + rand.N(d/2+1)
+}
+
+// sleepBackoff waits d before a retry. Unlike sleepContext it gives up
+// immediately when the context's deadline would pass during the wait, so a
+// retry is never started that could not finish.
+func sleepBackoff(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return context.DeadlineExceeded
+	}
+	return sleepContext(ctx, d)
+}
+
+// sleepContext waits for d, returning early with the context's error if it
+// is cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given either as a number of
+// seconds or as an HTTP date, returning zero when it is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// parseRetryConfig parses the retry section and max_retries of the plugin
+// config.
+func parseRetryConfig(raw map[string]any) RetryConfig {
+	var cfg RetryConfig
+	if n, ok := toInt(raw["max_retries"]); ok && n >= 0 {
+		cfg.MaxRetries = &n
+	}
+	retry, ok := raw["retry"].(map[string]any)
+	if !ok {
+		return cfg
+	}
+	cfg.NonIdempotent = helpers.NewConfigParser(retry).GetBool("non_idempotent", false)
+	codes, ok := retry["status_codes"].([]any)
+	if !ok {
+		return cfg
+	}
+	cfg.StatusCodes = []int{}
+	for _, v := range codes {
+		if code, ok := toInt(v); ok {
+			cfg.StatusCodes = append(cfg.StatusCodes, code)
+		}
+	}
+	return cfg
+}
+
+// validateRetryConfig checks that max_retries is a non-negative whole
+// number and that the configured status codes are HTTP error statuses.
+func validateRetryConfig(vb *helpers.ValidationBuilder, raw map[string]any) {
+	if v, ok := raw["max_retries"]; ok && v != nil {
+		if n, ok := toInt(v); !ok || n < 0 {
+			vb.AddError("max_retries", fmt.Sprintf("Invalid max_retries %v: must be a non-negative whole number", v))
+		}
+	}
+	retry, ok := raw["retry"].(map[string]any)
+	if !ok {
+		return
+	}
+	if v, ok := retry["non_idempotent"]; ok && v != nil {
+		if _, err := parseBool(v); err != nil {
+			vb.AddError("retry.non_idempotent", fmt.Sprintf("Invalid retry.non_idempotent: %v", err))
+		}
+	}
+	v, ok := retry["status_codes"]
+	if !ok || v == nil {
+		return
+	}
+	codes, ok := v.([]any)
+	if !ok {
+		vb.AddError("retry.status_codes", "retry.status_codes must be a list of HTTP status codes")
+		return
+	}
+	for _, c := range codes {
+		code, ok := toInt(c)
+		if !ok || code < 400 || code > 599 {
+			vb.AddError("retry.status_codes", fmt.Sprintf("Invalid retry status code %v: must be between 400 and 599", c))
+		}
+	}
+}
+
+// toInt converts a whole-number config value to an int.
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Keep tests that exercise API failures from waiting on real backoff
+	retryBaseDelay = time.Millisecond
+	os.Exit(m.Run())
+}
+
+func TestRetryConfigRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		codes    []int
+		status   int
+		expected bool
+	}{
+		{"default 503", nil, http.StatusServiceUnavailable, true},
+		{"default 429", nil, http.StatusTooManyRequests, true},
+		{"default 404", nil, http.StatusNotFound, false},
+		{"custom adds 408", []int{408, 503}, http.StatusRequestTimeout, true},
+		{"custom excludes 500", []int{502, 503}, http.StatusInternalServerError, false},
+		{"empty disables", []int{}, http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (RetryConfig{StatusCodes: tt.codes}).retryable(tt.status); got != tt.expected {
+				t.Errorf("retryable(%d) = %v, want %v", tt.status, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryConfigRetryableError(t *testing.T) {
+	urlErr := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://sentry.io/api/0/", Err: err}
+	}
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"retryable status", &StatusError{StatusCode: http.StatusBadGateway}, true},
+		{"connection refused", urlErr(&net.OpError{Op: "dial", Err: errors.New("connection refused")}), true},
+		{"closed before response", urlErr(io.EOF), true},
+		{"timeout", urlErr(context.DeadlineExceeded), true},
+		{"unknown host", urlErr(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}), false},
+		{"untrusted certificate", urlErr(&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}), false},
+		{"unsupported scheme", urlErr(errors.New("unsupported protocol scheme \"ftp\"")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (RetryConfig{}).retryableError(tt.err); got != tt.expected {
+				t.Errorf("retryableError(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryConfigRetriesRequest(t *testing.T) {
+	refused := &url.Error{Op: "Post", URL: "https://sentry.io/api/0/", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	timeout := &url.Error{Op: "Post", URL: "https://sentry.io/api/0/", Err: context.DeadlineExceeded}
+	tests := []struct {
+		name     string
+		retry    RetryConfig
+		method   string
+		err      error
+		expected bool
+	}{
+		{"GET 502", RetryConfig{}, http.MethodGet, &StatusError{StatusCode: http.StatusBadGateway}, true},
+		{"POST 429", RetryConfig{}, http.MethodPost, &StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"POST connection refused", RetryConfig{}, http.MethodPost, refused, true},
+		{"POST 502", RetryConfig{}, http.MethodPost, &StatusError{StatusCode: http.StatusBadGateway}, false},
+		{"POST timeout", RetryConfig{}, http.MethodPost, timeout, false},
+		{"POST 502 with non_idempotent", RetryConfig{NonIdempotent: true}, http.MethodPost, &StatusError{StatusCode: http.StatusBadGateway}, true},
+		{"POST timeout with non_idempotent", RetryConfig{NonIdempotent: true}, http.MethodPost, timeout, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.retry.retriesRequest(tt.method, tt.err); got != tt.expected {
+				t.Errorf("retriesRequest(%s, %v) = %v, want %v", tt.method, tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSentryClientRequestRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		retry     RetryConfig
+		status    int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{"recovers from 503", RetryConfig{}, http.StatusServiceUnavailable, 2, false},
+		{"does not retry 400", RetryConfig{}, http.StatusBadRequest, 1, true},
+		{"custom 408", RetryConfig{StatusCodes: []int{408}}, http.StatusRequestTimeout, 2, false},
+		{"excluded 500", RetryConfig{StatusCodes: []int{503}}, http.StatusInternalServerError, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"slug": "my-org"}`))
+			}))
+			defer server.Close()
+
+			client := &SentryClient{
+				baseURL:    server.URL,
+				authToken:  "test-token",
+				org:        "my-org",
+				httpClient: http.DefaultClient,
+				retry:      tt.retry,
+			}
+
+			_, err := client.GetOrganization(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOrganization() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, calls.Load())
+			}
+		})
+	}
+}
+
+func TestSentryClientRequestRetriesAfterSlowAttempt(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"slug": "my-org"}`))
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+		timeouts:   map[string]time.Duration{timeoutMetadata: 30 * time.Millisecond},
+	}
+	// The first attempt times out; the timeout applies per attempt, so the
+	// retry still gets its full 30ms
+	if _, err := client.GetOrganization(context.Background()); err != nil {
+		t.Fatalf("GetOrganization() error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected a retry after the slow attempt, got %d calls", calls.Load())
+	}
+}
+
+func TestSentryClientRequestRetriesIdempotentOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		retry     RetryConfig
+		wantCalls int32
+	}{
+		{"POST not retried by default", RetryConfig{}, 1},
+		{"non_idempotent retries POST", RetryConfig{NonIdempotent: true}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if calls.Add(1) == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"version": "1.0.0"}`))
+			}))
+			defer server.Close()
+
+			client := &SentryClient{baseURL: server.URL, authToken: "test-token", org: "my-org", httpClient: http.DefaultClient, retry: tt.retry}
+			_, _ = client.CreateRelease(context.Background(), "1.0.0", []string{"my-project"}, ReleaseOptions{})
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("expected %d POST calls, got %d", tt.wantCalls, calls.Load())
+			}
+		})
+	}
+}
+
+func TestSentryClientRequestRetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	if _, err := client.GetOrganization(context.Background()); err == nil {
+		t.Fatal("expected error after retries are exhausted")
+	}
+	if calls.Load() != defaultMaxRetries+1 {
+		t.Errorf("expected %d attempts, got %d", defaultMaxRetries+1, calls.Load())
+	}
+}
+
+func TestSentryClientRequestMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	maxRetries := 1
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+		retry:      RetryConfig{MaxRetries: &maxRetries},
+	}
+
+	if _, err := client.GetOrganization(context.Background()); err == nil {
+		t.Fatal("expected error after retries are exhausted")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 attempts with max_retries 1, got %d", calls.Load())
+	}
+}
+
+func TestSentryClientRequestRetriesConnectionErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// Drop the connection without a response
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"slug": "my-org"}`))
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	if _, err := client.GetOrganization(context.Background()); err != nil {
+		t.Fatalf("GetOrganization() error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected a retry after the dropped connection, got %d calls", calls.Load())
+	}
+}
+
+func TestSleepBackoffDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	if err := sleepBackoff(ctx, time.Hour); err == nil {
+		t.Fatal("expected an error when the backoff outlasts the deadline")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("expected sleepBackoff to return immediately, took %s", time.Since(start))
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := sleepBackoff(cancelled, time.Millisecond); err == nil {
+		t.Fatal("expected an error for a cancelled context")
+	}
+}
+
+func TestValidateRetryStatusCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		codes   any
+		wantErr bool
+	}{
+		{"valid", []any{408, 503.0}, false},
+		{"out of range", []any{200}, true},
+		{"not a number", []any{"503"}, true},
+		{"not a list", "503", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &SentryPlugin{}
+			resp, err := p.Validate(context.Background(), map[string]any{
+				"auth_token": "test-token",
+				"org":        "my-org",
+				"project":    "my-project",
+				"retry":      map[string]any{"status_codes": tt.codes},
+			})
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			gotErr := false
+			for _, e := range resp.Errors {
+				if e.Field == "retry.status_codes" {
+					gotErr = true
+				}
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("expected retry.status_codes error = %v, got %+v", tt.wantErr, resp.Errors)
+			}
+		})
+	}
+}
+
+func TestParseConfigRetry(t *testing.T) {
+	p := &SentryPlugin{}
+
+	if cfg := p.parseConfig(map[string]any{}); cfg.Retry.StatusCodes != nil {
+		t.Errorf("expected default retry codes, got %v", cfg.Retry.StatusCodes)
+	}
+	cfg := p.parseConfig(map[string]any{"retry": map[string]any{"status_codes": []any{408.0, 503}}})
+	if len(cfg.Retry.StatusCodes) != 2 || cfg.Retry.StatusCodes[0] != 408 || cfg.Retry.StatusCodes[1] != 503 {
+		t.Errorf("unexpected retry codes: %v", cfg.Retry.StatusCodes)
+	}
+	if cfg.Retry.maxAttempts() != defaultMaxRetries+1 {
+		t.Errorf("expected %d attempts by default, got %d", defaultMaxRetries+1, cfg.Retry.maxAttempts())
+	}
+
+	if cfg.Retry.NonIdempotent {
+		t.Error("expected POST requests not to be retried by default")
+	}
+	if cfg := p.parseConfig(map[string]any{"retry": map[string]any{"non_idempotent": true}}); !cfg.Retry.NonIdempotent {
+		t.Error("expected retry.non_idempotent to be parsed")
+	}
+
+	cfg = p.parseConfig(map[string]any{"max_retries": 0})
+	if cfg.Retry.maxAttempts() != 1 {
+		t.Errorf("expected max_retries 0 to disable retries, got %d attempts", cfg.Retry.maxAttempts())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"missing", "", 0},
+		{"seconds", "30", 30 * time.Second},
+		{"negative", "-5", 0},
+		{"http date", now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{"past date", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"invalid", "soon", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSentryClientRequestRateLimited(t *testing.T) {
+	t.Run("retries after Retry-After", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"slug": "my-org"}`))
+		}))
+		defer server.Close()
+
+		client := &SentryClient{baseURL: server.URL, authToken: "test-token", org: "my-org", httpClient: http.DefaultClient}
+		if _, err := client.GetOrganization(context.Background()); err != nil {
+			t.Fatalf("GetOrganization() error = %v", err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("expected a retry after 429, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("retries a POST after Retry-After", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": "1", "environment": "production"}`))
+		}))
+		defer server.Close()
+
+		client := &SentryClient{baseURL: server.URL, authToken: "test-token", org: "my-org", httpClient: http.DefaultClient}
+		if _, err := client.CreateDeploy(context.Background(), "1.0.0", DeployConfig{Environment: "production"}); err != nil {
+			t.Fatalf("CreateDeploy() error = %v", err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("expected a retry after 429, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("returns a typed error when the wait outlasts the context", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := &SentryClient{baseURL: server.URL, authToken: "test-token", org: "my-org", httpClient: http.DefaultClient}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err := client.GetOrganization(ctx)
+		var rateErr *RateLimitError
+		if !errors.As(err, &rateErr) || rateErr.RetryAfter != 2*time.Minute {
+			t.Fatalf("expected RateLimitError with a 2m Retry-After, got %v", err)
+		}
+		if !isRateLimited(err) {
+			t.Error("expected isRateLimited to recognize RateLimitError")
+		}
+		if calls.Load() != 1 {
+			t.Errorf("expected no retry past the deadline, got %d calls", calls.Load())
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Release channels derived from a version's prerelease identifier.
+const (
+	channelStable = "stable"
+	channelBeta   = "beta"
+	channelAlpha  = "alpha"
+)
+
+// defaultChannels maps prerelease identifiers to release channels.
+var defaultChannels = map[string]string{
+	"rc":    channelBeta,
+	"beta":  channelBeta,
+	"alpha": channelAlpha,
+}
+
+// semverPattern matches a Semantic Versioning 2.0.0 version, from semver.org.
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// isSemver reports whether version is a strict semantic version. Unlike
+// isPrerelease, a leading "v" is not accepted.
+func isSemver(version string) bool {
+	return semverPattern.MatchString(version)
+}
+
+// isPrerelease reports whether a semantic version has a prerelease segment,
+// e.g. "1.2.3-rc.1". A leading "v" and build metadata are ignored.
+func isPrerelease(version string) bool {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+	i := strings.Index(version, "-")
+	return i > 0 && i < len(version)-1
+}
+
+// prereleaseIdentifier returns the leading alphabetic part of a version's
+// prerelease segment, e.g. "rc" for "1.2.3-rc.1" or "beta" for "2.0.0-beta2".
+func prereleaseIdentifier(version string) string {
+	if !isPrerelease(version) {
+		return ""
+	}
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+	pre := version[strings.Index(version, "-")+1:]
+	if i := strings.IndexFunc(pre, func(r rune) bool { return !unicode.IsLetter(r) }); i >= 0 {
+		pre = pre[:i]
+	}
+	return strings.ToLower(pre)
+}
+
+// deriveChannel returns the release channel for a version: stable without a
+// prerelease segment, otherwise the channel its prerelease identifier maps to.
+// The overrides take precedence over the default mapping, and an unmapped
+// identifier is used as the channel name.
+func deriveChannel(version string, overrides map[string]string) string {
+	if !isPrerelease(version) {
+		return channelStable
+	}
+	id := prereleaseIdentifier(version)
+	if channel, ok := overrides[id]; ok {
+		return channel
+	}
+	if channel, ok := defaultChannels[id]; ok {
+		return channel
+	}
+	if id == "" {
+		return channelBeta
+	}
+	return id
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestIsPrerelease(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"1.2.3", false},
+		{"v1.2.3", false},
+		{"1.2.3-rc.1", true},
+		{"v2.0.0-beta", true},
+		{"1.2.3+build.5", false},
+		{"1.2.3-alpha+build.5", true},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPrerelease(tt.version); got != tt.expected {
+			t.Errorf("isPrerelease(%q) = %v, want %v", tt.version, got, tt.expected)
+		}
+	}
+}
+
+func TestIsSemver(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"1.2.3", true},
+		{"1.2.3-rc.1", true},
+		{"1.2.3-alpha+build.5", true},
+		{"v1.2.3", false},
+		{"2024.06.1", false},
+		{"1.2", false},
+		{"my-app@1.2.3", false},
+		{"1.2.3-01", false},
+	}
+
+	for _, tt := range tests {
+		if got := isSemver(tt.version); got != tt.expected {
+			t.Errorf("isSemver(%q) = %v, want %v", tt.version, got, tt.expected)
+		}
+	}
+}
+
+func TestDeriveChannel(t *testing.T) {
+	tests := []struct {
+		version   string
+		overrides map[string]string
+		expected  string
+	}{
+		{"1.2.3", nil, "stable"},
+		{"v1.2.3+build.5", nil, "stable"},
+		{"1.2.3-rc.1", nil, "beta"},
+		{"1.2.3-RC1", nil, "beta"},
+		{"2.0.0-beta.2", nil, "beta"},
+		{"2.0.0-alpha", nil, "alpha"},
+		{"2.0.0-nightly.20240101", nil, "nightly"},
+		{"1.2.3-rc.1", map[string]string{"rc": "candidate"}, "candidate"},
+	}
+
+	for _, tt := range tests {
+		if got := deriveChannel(tt.version, tt.overrides); got != tt.expected {
+			t.Errorf("deriveChannel(%q) = %q, want %q", tt.version, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultPruneOlderThan is the default age after which release files are pruned.
+const defaultPruneOlderThan = 30 * 24 * time.Hour
+
+// Source map defaults used when sourcemaps.path and sourcemaps.url_prefix
+// are not set.
+const (
+	defaultSourcemapPath      = "./dist"
+	defaultSourcemapURLPrefix = "~/"
+)
+
+// defaultContentType is uploaded for release files with an unknown extension.
+const defaultContentType = "application/octet-stream"
+
+// defaultContentTypes maps release file extensions to the content type
+// uploaded for them.
+var defaultContentTypes = map[string]string{
+	".js":  "application/javascript",
+	".mjs": "application/javascript",
+	".cjs": "application/javascript",
+	".map": "application/json",
+}
+
+// defaultSourcemapInclude selects the files uploaded when
+// sourcemaps.include is not set.
+var defaultSourcemapInclude = []string{"**/*.js", "**/*.mjs", "**/*.cjs", "**/*.map"}
+
+// sourcemapFiles returns the files under cfg.Path to upload, as
+// slash-separated paths relative to it, in lexical order. A file is
+// uploaded when it matches an include glob and no exclude glob.
+func sourcemapFiles(cfg SourcemapsConfig) ([]string, error) {
+	include := cfg.Include
+	if len(include) == 0 {
+		include = defaultSourcemapInclude
+	}
+
+	var files []string
+	err := filepath.WalkDir(cfg.Path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(cfg.Path, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if matchAnyGlob(include, rel) && !matchAnyGlob(cfg.Exclude, rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source maps in %s: %w", cfg.Path, err)
+	}
+	return files, nil
+}
+
+// releaseFileName returns the release file name for a file uploaded from
+// rel, with the URL prefix prepended, e.g. "~/static/app.js.map".
+func releaseFileName(urlPrefix, rel string) string {
+	if urlPrefix != "" && !strings.HasSuffix(urlPrefix, "/") {
+		urlPrefix += "/"
+	}
+	return urlPrefix + rel
+}
+
+// releaseFileContentType returns the content type to upload a release file
+// with. Longer extensions match first, so ".js.map" can be configured apart
+// from ".map", and overrides take precedence over the defaults.
+func releaseFileContentType(name string, overrides map[string]string) string {
+	base := strings.ToLower(path.Base(name))
+	for i := strings.Index(base, "."); i >= 0; {
+		ext := base[i:]
+		if ct, ok := overrides[ext]; ok {
+			return ct
+		}
+		if ct, ok := defaultContentTypes[ext]; ok {
+			return ct
+		}
+		next := strings.Index(ext[1:], ".")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return defaultContentType
+}
+
+// normalizeExtension lowercases a configured extension and adds the
+// leading dot if it is missing.
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// pruneResult summarizes a release file prune.
+type pruneResult struct {
+	Files int
+	Bytes int64
+}
+
+// staleReleaseFiles returns the files created before the cutoff, except
+// those named in keep.
+func staleReleaseFiles(files []ReleaseFile, cutoff time.Time, keep map[string]bool) []ReleaseFile {
+	var stale []ReleaseFile
+	for _, f := range files {
+		if !f.DateCreated.IsZero() && f.DateCreated.Before(cutoff) && !keep[f.Name] {
+			stale = append(stale, f)
+		}
+	}
+	return stale
+}
+
+// listStaleReleaseFiles lists the release files pruneReleaseFiles would
+// delete.
+func listStaleReleaseFiles(ctx context.Context, client *SentryClient, version string, maxAge time.Duration, keep map[string]bool) ([]ReleaseFile, error) {
+	files, err := client.ListReleaseFiles(ctx, version, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list release files: %w", err)
+	}
+	return staleReleaseFiles(files, time.Now().Add(-maxAge), keep), nil
+}
+
+// pruneReleaseFiles deletes release files older than maxAge, except those
+// named in keep, fanning the deletes out within the concurrency limits and
+// with the given stagger between request starts.
+func pruneReleaseFiles(ctx context.Context, client *SentryClient, version string, maxAge time.Duration, keep map[string]bool, concurrency ConcurrencyConfig, stagger time.Duration) (pruneResult, error) {
+	var result pruneResult
+
+	stale, err := listStaleReleaseFiles(ctx, client, version, maxAge, keep)
+	if err != nil {
+		return result, err
+	}
+	errs, spread := fanOut(ctx, len(stale), concurrency, stagger, func(ctx context.Context, i int) error {
+		return client.DeleteReleaseFile(ctx, version, stale[i].ID)
+	})
+	client.debug.logFanOut("prune release files", len(stale), spread)
+
+	var firstErr error
+	for i, f := range stale {
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to delete release file %s: %w", f.Name, errs[i])
+			}
+			continue
+		}
+		result.Files++
+		result.Bytes += f.Size
+	}
+
+	return result, firstErr
+}
+
+// matchGlob reports whether a slash-separated path matches the glob pattern.
+// In addition to path.Match syntax, a "**" segment matches zero or more
+// path segments.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchAnyGlob reports whether name matches any of the patterns.
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
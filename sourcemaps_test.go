@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestStaleReleaseFiles(t *testing.T) {
+	now := time.Now()
+	files := []ReleaseFile{
+		{ID: "1", Name: "~/old.js.map", DateCreated: now.Add(-60 * 24 * time.Hour)},
+		{ID: "2", Name: "~/new.js.map", DateCreated: now.Add(-time.Hour)},
+		{ID: "3", Name: "~/unknown.js.map"},
+	}
+
+	stale := staleReleaseFiles(files, now.Add(-defaultPruneOlderThan), nil)
+	if len(stale) != 1 || stale[0].ID != "1" {
+		t.Errorf("expected only file 1 to be stale, got %+v", stale)
+	}
+
+	if kept := staleReleaseFiles(files, now.Add(-defaultPruneOlderThan), map[string]bool{"~/old.js.map": true}); len(kept) != 0 {
+		t.Errorf("expected kept files not to be stale, got %+v", kept)
+	}
+}
+
+func TestPruneReleaseFiles(t *testing.T) {
+	var mu sync.Mutex
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			files := []map[string]any{
+				{"id": "1", "name": "~/old.js.map", "size": 100, "dateCreated": time.Now().Add(-60 * 24 * time.Hour)},
+				{"id": "2", "name": "~/older.js.map", "size": 50, "dateCreated": time.Now().Add(-90 * 24 * time.Hour)},
+				{"id": "3", "name": "~/new.js.map", "size": 10, "dateCreated": time.Now()},
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(files)
+		case http.MethodDelete:
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	result, err := pruneReleaseFiles(context.Background(), client, "1.0.0", defaultPruneOlderThan, nil, ConcurrencyConfig{}, 0)
+	if err != nil {
+		t.Fatalf("pruneReleaseFiles() error = %v", err)
+	}
+
+	if result.Files != 2 || result.Bytes != 150 {
+		t.Errorf("expected 2 files and 150 bytes pruned, got %d files and %d bytes", result.Files, result.Bytes)
+	}
+	sort.Strings(deleted)
+	if len(deleted) != 2 || deleted[0] != "/api/0/organizations/my-org/releases/1.0.0/files/1/" {
+		t.Errorf("unexpected DELETE requests: %v", deleted)
+	}
+}
+
+func TestReleaseFileContentType(t *testing.T) {
+	overrides := map[string]string{".js.map": "application/octet-stream", ".txt": "text/plain"}
+
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		expected  string
+	}{
+		{"~/static/app.js", nil, "application/javascript"},
+		{"~/static/app.min.js.map", nil, "application/json"},
+		{"~/static/APP.MJS", nil, "application/javascript"},
+		{"~/static/app.css.map", overrides, "application/json"},
+		{"~/static/app.js.map", overrides, "application/octet-stream"},
+		{"~/static/notes.txt", overrides, "text/plain"},
+		{"~/static/logo.png", nil, defaultContentType},
+		{"~/static/LICENSE", nil, defaultContentType},
+	}
+
+	for _, tt := range tests {
+		if got := releaseFileContentType(tt.name, tt.overrides); got != tt.expected {
+			t.Errorf("releaseFileContentType(%q) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestSentryClientUploadReleaseFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/0/organizations/my-org/releases/1.0.0/files/" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm() error = %v", err)
+		}
+		if name := r.FormValue("name"); name != "~/static/app.js.map" {
+			t.Errorf("unexpected name field %q", name)
+		}
+		file := r.MultipartForm.File["file"]
+		if len(file) != 1 || file[0].Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected file part: %+v", file)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "1", "name": "~/static/app.js.map", "size": 2}`))
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	file, err := client.UploadReleaseFile(context.Background(), "1.0.0", "~/static/app.js.map", []byte("{}"), "application/json")
+	if err != nil {
+		t.Fatalf("UploadReleaseFile() error = %v", err)
+	}
+	if file.ID != "1" {
+		t.Errorf("expected file ID 1, got %q", file.ID)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.js", "app.js", true},
+		{"*.js", "dist/app.js", false},
+		{"dist/*.map", "dist/app.js.map", true},
+		{"web/**", "web/src/app.ts", true},
+		{"web/**", "api/main.go", false},
+		{"**/*.map", "app.js.map", true},
+		{"**/*.map", "dist/js/app.js.map", true},
+		{"packages/*/src/**", "packages/ui/src/button.tsx", true},
+		{"packages/*/src/**", "packages/ui/README.md", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSentryClientListReleaseFilesPrefix(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		w.Header().Set("Content-Type", "application/json")
+		// Sentry's query matches anywhere in the name
+		_, _ = w.Write([]byte(`[{"id": "1", "name": "~/static/app.js"}, {"id": "2", "name": "~/other/~/static/app.js"}]`))
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	files, err := client.ListReleaseFiles(context.Background(), "1.0.0", "~/static/")
+	if err != nil {
+		t.Fatalf("ListReleaseFiles() error = %v", err)
+	}
+	if query != "~/static/" {
+		t.Errorf("expected prefix to be sent as query, got %q", query)
+	}
+	if len(files) != 1 || files[0].ID != "1" {
+		t.Errorf("expected only files starting with the prefix, got %+v", files)
+	}
+}
+
+func TestSentryClientUploadSourcemaps(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"app.js":               "console.log(1)",
+		"app.js.map":           "{}",
+		"static/vendor.js.map": "{}",
+		"static/style.css":     "body{}",
+		"test/app.test.js":     "test()",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	var names []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm() error = %v", err)
+		}
+		mu.Lock()
+		names = append(names, r.FormValue("name"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ReleaseFile{ID: "1", Name: r.FormValue("name")})
+	}))
+	defer server.Close()
+
+	client := &SentryClient{
+		baseURL:    server.URL,
+		authToken:  "test-token",
+		org:        "my-org",
+		httpClient: http.DefaultClient,
+	}
+
+	uploaded, err := client.UploadSourcemaps(context.Background(), "1.0.0", SourcemapsConfig{
+		Path:      dir,
+		URLPrefix: "~/assets",
+		Exclude:   []string{"test/**"},
+	})
+	if err != nil {
+		t.Fatalf("UploadSourcemaps() error = %v", err)
+	}
+	want := []string{"~/assets/app.js", "~/assets/app.js.map", "~/assets/static/vendor.js.map"}
+	if len(uploaded) != len(want) || !slices.Equal(names, want) {
+		t.Errorf("uploaded %v, want %v", names, want)
+	}
+}
+
+func TestParseConfigSourcemapDefaults(t *testing.T) {
+	p := &SentryPlugin{}
+	cfg := p.parseConfig(map[string]any{"upload_sourcemaps": true})
+	if cfg.Sourcemaps.Path != defaultSourcemapPath || cfg.Sourcemaps.URLPrefix != defaultSourcemapURLPrefix {
+		t.Errorf("expected source map defaults without a sourcemaps section, got %+v", cfg.Sourcemaps)
+	}
+}
+
+func TestValidateSourcemapPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.js")
+	if err := os.WriteFile(file, []byte("console.log(1)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"directory", dir, false},
+		{"missing", filepath.Join(dir, "missing"), true},
+		{"file", file, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &SentryPlugin{}
+			resp, err := p.Validate(context.Background(), map[string]any{
+				"auth_token":        "test-token",
+				"org":               "my-org",
+				"project":           "my-project",
+				"url":               "http://127.0.0.1:0",
+				"upload_sourcemaps": true,
+				"sourcemaps":        map[string]any{"path": tt.path},
+			})
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			gotErr := false
+			for _, e := range resp.Errors {
+				if e.Field == "sourcemaps.path" {
+					gotErr = true
+				}
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("expected sourcemaps.path error = %v, got %+v", tt.wantErr, resp.Errors)
+			}
+		})
+	}
+}
+
+func TestExecutePrePublishPruneKeepsUploadedFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.js.map"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/files/"):
+			// The upload is listed as created a moment ago
+			created := time.Now().Add(-time.Minute)
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"id": "1", "name": "~/app.js.map", "size": 2, "dateCreated": created},
+				{"id": "2", "name": "~/stale.js.map", "size": 5, "dateCreated": created},
+			})
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodDelete:
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/files/"):
+			_ = json.NewEncoder(w).Encode(ReleaseFile{ID: "1", Name: r.FormValue("name")})
+		default:
+			_, _ = w.Write([]byte(`{"version": "1.0.0"}`))
+		}
+	}))
+	defer server.Close()
+
+	p := &SentryPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPrePublish,
+		Config: map[string]any{
+			"auth_token":        "test-token",
+			"org":               "my-org",
+			"project":           "my-project",
+			"url":               server.URL,
+			"ci_metadata":       false,
+			"upload_sourcemaps": true,
+			"sourcemaps": map[string]any{
+				"path":             dir,
+				"prune":            true,
+				"prune_older_than": "1s",
+			},
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("Execute() error = %v, %+v", err, resp)
+	}
+	if len(deleted) != 1 || deleted[0] != "/api/0/organizations/my-org/releases/1.0.0/files/2/" {
+		t.Errorf("expected only the stale file to be deleted, got %v", deleted)
+	}
+}
+
+func TestExecutePrePublishPruneDryRun(t *testing.T) {
+	var deletes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete:
+			deletes++
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/files/"):
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"id": "1", "name": "~/old.js.map", "size": 100, "dateCreated": time.Now().Add(-48 * time.Hour)},
+				{"id": "2", "name": "~/older.js.map", "size": 50, "dateCreated": time.Now().Add(-72 * time.Hour)},
+				{"id": "3", "name": "~/new.js.map", "size": 10, "dateCreated": time.Now()},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := &SentryPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:   plugin.HookPrePublish,
+		DryRun: true,
+		Config: map[string]any{
+			"auth_token":  "test-token",
+			"org":         "my-org",
+			"project":     "my-project",
+			"url":         server.URL,
+			"ci_metadata": false,
+			"sourcemaps": map[string]any{
+				"prune":            true,
+				"prune_older_than": "24h",
+			},
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("Execute() error = %v, %+v", err, resp)
+	}
+	if deletes != 0 {
+		t.Errorf("dry run deleted %d files", deletes)
+	}
+	if resp.Outputs["pruned_files"] != 2 || resp.Outputs["pruned_bytes"] != int64(150) {
+		t.Errorf("unexpected outputs: %v", resp.Outputs)
+	}
+	if !strings.Contains(resp.Message, "Would prune 2 release files older than 24h0m0s (150 bytes)") {
+		t.Errorf("unexpected message: %s", resp.Message)
+	}
+}
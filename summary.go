@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// stepSummary holds the data rendered into a GitHub Actions job summary.
+type stepSummary struct {
+	Version     string
+	ReleaseURL  string
+	Environment string
+	DeployURL   string
+	Commits     int
+}
+
+// markdown renders the summary as a Markdown section.
+func (s stepSummary) markdown() string {
+	var b strings.Builder
+	b.WriteString("### Sentry release\n\n")
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Release | [%s](%s) |\n", s.Version, s.ReleaseURL)
+	if s.DeployURL != "" {
+		fmt.Fprintf(&b, "| Deploy | [%s](%s) |\n", s.Environment, s.DeployURL)
+	}
+	fmt.Fprintf(&b, "| Commits | %d |\n", s.Commits)
+	return b.String()
+}
+
+// writeStepSummary appends the summary to the file at path.
+// An empty path is a no-op, so callers need not check whether the
+// job is running in GitHub Actions.
+func writeStepSummary(path string, s stepSummary) error {
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.WriteString(s.markdown() + "\n"); err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestWriteStepSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+
+	s := stepSummary{
+		Version:     "1.0.0",
+		ReleaseURL:  "https://sentry.io/organizations/my-org/releases/1.0.0/",
+		Environment: "production",
+		DeployURL:   "https://sentry.io/organizations/my-org/releases/1.0.0/?environment=production",
+		Commits:     3,
+	}
+	if err := writeStepSummary(path, s); err != nil {
+		t.Fatalf("writeStepSummary() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read summary: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{"[1.0.0](" + s.ReleaseURL + ")", "[production](" + s.DeployURL + ")", "| Commits | 3 |"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("summary missing %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestWriteStepSummaryNoPath(t *testing.T) {
+	if err := writeStepSummary("", stepSummary{Version: "1.0.0"}); err != nil {
+		t.Errorf("writeStepSummary() with empty path error = %v", err)
+	}
+}
+
+func TestExecutePostPublishWritesSummary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/deploys/") {
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "1", "environment": "production"})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "summary.md")
+
+	p := &SentryPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"auth_token": "test-token",
+			"org":        "my-org",
+			"project":    "my-project",
+			"url":        server.URL,
+			"summary":    true,
+		},
+		Context: plugin.ReleaseContext{
+			Version:     "1.0.0",
+			Environment: map[string]string{"GITHUB_STEP_SUMMARY": path},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("Execute() success = false: %s", resp.Error)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected summary file to be written: %v", err)
+	}
+	if !strings.Contains(string(data), server.URL+"/organizations/my-org/releases/1.0.0/") {
+		t.Errorf("summary should link to the release, got:\n%s", data)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// insecureSkipVerifyWarning is reported whenever insecure_skip_verify is on.
+const insecureSkipVerifyWarning = "insecure_skip_verify is enabled: the Sentry server's TLS certificate is not verified, so the auth token can be intercepted; use ca_cert_path instead outside development"
+
+// readCACertPool loads the PEM-encoded certificates in path into a
+// certificate pool for verifying the Sentry server.
+func readCACertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("invalid CA certificate file %s: no PEM certificates found", path)
+	}
+	return pool, nil
+}
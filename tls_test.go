@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// writeServerCert writes the TLS test server's certificate as a PEM file.
+func writeServerCert(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewSentryClientRootCAs(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"slug": "my-org"}`))
+	}))
+	defer server.Close()
+
+	untrusted := NewSentryClient(server.URL, "test-token", "my-org", ClientOptions{})
+	if _, err := untrusted.GetOrganization(context.Background()); err == nil {
+		t.Fatal("expected the self-signed certificate to be rejected by default")
+	}
+
+	pool, err := readCACertPool(writeServerCert(t, server))
+	if err != nil {
+		t.Fatalf("readCACertPool() error = %v", err)
+	}
+	trusted := NewSentryClient(server.URL, "test-token", "my-org", ClientOptions{RootCAs: pool})
+	if _, err := trusted.GetOrganization(context.Background()); err != nil {
+		t.Errorf("GetOrganization() with ca_cert_path error = %v", err)
+	}
+
+	insecure := NewSentryClient(server.URL, "test-token", "my-org", ClientOptions{InsecureSkipVerify: true})
+	if _, err := insecure.GetOrganization(context.Background()); err != nil {
+		t.Errorf("GetOrganization() with insecure_skip_verify error = %v", err)
+	}
+}
+
+func TestValidateCACertPath(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{notPEM, filepath.Join(dir, "missing.pem")} {
+		p := &SentryPlugin{}
+		resp, err := p.Validate(context.Background(), map[string]any{
+			"auth_token":   "test-token",
+			"org":          "my-org",
+			"project":      "my-project",
+			"ca_cert_path": path,
+		})
+		if err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		if resp.Valid || len(resp.Errors) == 0 || resp.Errors[0].Field != "ca_cert_path" {
+			t.Errorf("expected a ca_cert_path error for %s, got %+v", path, resp.Errors)
+		}
+	}
+}
+
+func TestExecuteInsecureSkipVerifyWarning(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	p := &SentryPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPostPublish,
+		Config: map[string]any{
+			"auth_token":           "test-token",
+			"org":                  "my-org",
+			"project":              "my-project",
+			"url":                  server.URL,
+			"set_commits":          false,
+			"create_deploy":        false,
+			"insecure_skip_verify": true,
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got %s", resp.Error)
+	}
+	if !strings.Contains(resp.Message, "Warning: insecure_skip_verify is enabled") {
+		t.Errorf("expected insecure_skip_verify warning, got: %s", resp.Message)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+// webhookTimeout bounds the finalize webhook, including retries.
+const webhookTimeout = 10 * time.Second
+
+// WebhookConfig contains the finalize webhook settings.
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// FailOnError fails the hook when the webhook cannot be delivered,
+	// instead of reporting a warning.
+	FailOnError bool `json:"fail_on_error"`
+}
+
+// finalizeWebhookPayload is the JSON body posted after a release is
+// finalized.
+type finalizeWebhookPayload struct {
+	Version    string   `json:"version"`
+	Projects   []string `json:"projects"`
+	ReleaseURL string   `json:"release_url"`
+	DeployURL  string   `json:"deploy_url,omitempty"`
+}
+
+// releaseCreatedEvent identifies release webhook payloads.
+const releaseCreatedEvent = "release.created"
+
+// releaseCreatedPayload is the JSON body posted after a release is created.
+type releaseCreatedPayload struct {
+	Event      string   `json:"event"`
+	Release    *Release `json:"release"`
+	Projects   []string `json:"projects"`
+	ReleaseURL string   `json:"release_url"`
+}
+
+// sendWebhook posts payload as JSON to url, retrying error responses that
+// the retry config marks as retryable.
+func sendWebhook(ctx context.Context, url string, payload any, retry RetryConfig) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		status, err := postWebhook(ctx, url, body)
+		if err == nil && status < 400 {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("webhook returned status %d", status)
+			if attempt < retry.maxAttempts() && retry.retryable(status) && sleepBackoff(ctx, retryDelay(attempt)) == nil {
+				continue
+			}
+		}
+		return err
+	}
+}
+
+// postWebhook performs a single webhook delivery and returns the response
+// status.
+func postWebhook(ctx context.Context, url string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// parseWebhookConfig parses a webhook setting such as finalize_webhook, given either as a URL or as a
+// map with url and fail_on_error.
+func parseWebhookConfig(raw any) WebhookConfig {
+	switch v := raw.(type) {
+	case string:
+		return WebhookConfig{URL: strings.TrimSpace(v)}
+	case map[string]any:
+		parser := helpers.NewConfigParser(v)
+		return WebhookConfig{
+			URL:         strings.TrimSpace(parser.GetString("url", "", "")),
+			FailOnError: parser.GetBool("fail_on_error", false),
+		}
+	}
+	return WebhookConfig{}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestSendWebhookRetries(t *testing.T) {
+	var calls atomic.Int32
+	var payload finalizeWebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	err := sendWebhook(context.Background(), server.URL, finalizeWebhookPayload{Version: "1.0.0", Projects: []string{"web"}}, RetryConfig{})
+	if err != nil {
+		t.Fatalf("sendWebhook() error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected a retry after 503, got %d calls", calls.Load())
+	}
+	if payload.Version != "1.0.0" || len(payload.Projects) != 1 {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestParseWebhookConfig(t *testing.T) {
+	if cfg := parseWebhookConfig("https://hooks.example.com/sentry"); cfg.URL != "https://hooks.example.com/sentry" || cfg.FailOnError {
+		t.Errorf("unexpected config from URL: %+v", cfg)
+	}
+	cfg := parseWebhookConfig(map[string]any{"url": "https://hooks.example.com/sentry", "fail_on_error": true})
+	if cfg.URL != "https://hooks.example.com/sentry" || !cfg.FailOnError {
+		t.Errorf("unexpected config from map: %+v", cfg)
+	}
+}
+
+func TestExecutePostPublishFinalizeWebhook(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer hook.Close()
+
+	sentry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer sentry.Close()
+
+	tests := []struct {
+		name        string
+		failOnError bool
+		wantSuccess bool
+	}{
+		{"warning by default", false, true},
+		{"fail_on_error", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &SentryPlugin{}
+			resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+				Hook: plugin.HookPostPublish,
+				Config: map[string]any{
+					"auth_token":    "test-token",
+					"org":           "my-org",
+					"project":       "my-project",
+					"url":           sentry.URL,
+					"set_commits":   false,
+					"create_deploy": false,
+					"finalize_webhook": map[string]any{
+						"url":           hook.URL,
+						"fail_on_error": tt.failOnError,
+					},
+				},
+				Context: plugin.ReleaseContext{Version: "1.0.0"},
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if resp.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v (%s%s)", resp.Success, tt.wantSuccess, resp.Message, resp.Error)
+			}
+			if tt.wantSuccess && !strings.Contains(resp.Message, "Warning: Finalize webhook failed") {
+				t.Errorf("expected webhook warning, got: %s", resp.Message)
+			}
+		})
+	}
+}
+
+func TestExecutePrePublishReleaseWebhook(t *testing.T) {
+	var payload releaseCreatedPayload
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer hook.Close()
+
+	sentry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version": "1.0.0", "ref": "abc123"}`))
+	}))
+	defer sentry.Close()
+
+	p := &SentryPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookPrePublish,
+		Config: map[string]any{
+			"auth_token":      "test-token",
+			"org":             "my-org",
+			"project":         "my-project",
+			"url":             sentry.URL,
+			"ci_metadata":     false,
+			"release_webhook": hook.URL,
+		},
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !resp.Success || !strings.Contains(resp.Message, "Sent release webhook") {
+		t.Fatalf("expected release webhook to be sent, got %s %s", resp.Message, resp.Error)
+	}
+	if payload.Event != releaseCreatedEvent || payload.Release == nil || payload.Release.Ref != "abc123" {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+	if len(payload.Projects) != 1 || payload.Projects[0] != "my-project" {
+		t.Errorf("unexpected payload projects: %v", payload.Projects)
+	}
+}